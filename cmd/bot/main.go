@@ -8,9 +8,60 @@ import (
 	"os/signal"
 	"syscall"
 	"taskchord/internal/discord"
+	calendarCtrl "taskchord/internal/pkg/calendar/ctrl"
+	calendarEnt "taskchord/internal/pkg/calendar/ent"
+	calendarSvc "taskchord/internal/pkg/calendar/svc"
+	digestCtrl "taskchord/internal/pkg/digest/ctrl"
+	digestEnt "taskchord/internal/pkg/digest/ent"
+	digestSvc "taskchord/internal/pkg/digest/svc"
+	exportCtrl "taskchord/internal/pkg/export/ctrl"
+	exportSvc "taskchord/internal/pkg/export/svc"
+	fieldCtrl "taskchord/internal/pkg/field/ctrl"
+	fieldEnt "taskchord/internal/pkg/field/ent"
+	fieldSvc "taskchord/internal/pkg/field/svc"
+	guildCtrl "taskchord/internal/pkg/guild/ctrl"
+	guildEnt "taskchord/internal/pkg/guild/ent"
+	guildSvc "taskchord/internal/pkg/guild/svc"
+	importerCtrl "taskchord/internal/pkg/importer/ctrl"
+	importerEnt "taskchord/internal/pkg/importer/ent"
+	importerSvc "taskchord/internal/pkg/importer/svc"
+	milestoneCtrl "taskchord/internal/pkg/milestone/ctrl"
+	milestoneEnt "taskchord/internal/pkg/milestone/ent"
+	milestoneSvc "taskchord/internal/pkg/milestone/svc"
+	notifyCtrl "taskchord/internal/pkg/notify/ctrl"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+	notifySvc "taskchord/internal/pkg/notify/svc"
+	priorityCtrl "taskchord/internal/pkg/priority/ctrl"
+	priorityEnt "taskchord/internal/pkg/priority/ent"
+	prioritySvc "taskchord/internal/pkg/priority/svc"
+	projectCtrl "taskchord/internal/pkg/project/ctrl"
+	projectEnt "taskchord/internal/pkg/project/ent"
+	projectSvc "taskchord/internal/pkg/project/svc"
+	searchCtrl "taskchord/internal/pkg/search/ctrl"
+	searchSvc "taskchord/internal/pkg/search/svc"
+	statsCtrl "taskchord/internal/pkg/stats/ctrl"
+	statsSvc "taskchord/internal/pkg/stats/svc"
 	"taskchord/internal/pkg/task/ctrl"
 	taskEnt "taskchord/internal/pkg/task/ent"
 	"taskchord/internal/pkg/task/svc"
+	templateCtrl "taskchord/internal/pkg/template/ctrl"
+	templateEnt "taskchord/internal/pkg/template/ent"
+	templateSvc "taskchord/internal/pkg/template/svc"
+	trackCtrl "taskchord/internal/pkg/track/ctrl"
+	trackEnt "taskchord/internal/pkg/track/ent"
+	trackSvc "taskchord/internal/pkg/track/svc"
+	userCtrl "taskchord/internal/pkg/user/ctrl"
+	userEnt "taskchord/internal/pkg/user/ent"
+	userSvc "taskchord/internal/pkg/user/svc"
+	viewCtrl "taskchord/internal/pkg/view/ctrl"
+	viewEnt "taskchord/internal/pkg/view/ent"
+	viewSvc "taskchord/internal/pkg/view/svc"
+	workflowCtrl "taskchord/internal/pkg/workflow/ctrl"
+	workflowEnt "taskchord/internal/pkg/workflow/ent"
+	workflowSvc "taskchord/internal/pkg/workflow/svc"
+	"taskchord/internal/web"
+	"time"
+	_ "time/tzdata" // Time zones must resolve even on hosts without a zoneinfo database
 )
 
 func main() {
@@ -31,22 +82,127 @@ func main() {
 		log.Fatal("DATABASE_URL is not set")
 	}
 
+	// Timers left running longer than this are stopped automatically
+	idleLimit := 8 * time.Hour
+	if value := os.Getenv("TRACK_IDLE_LIMIT"); value != "" {
+		idleLimit, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("TRACK_IDLE_LIMIT is not a valid duration: %v", err)
+		}
+	}
+
+	// Calendar feeds are served over HTTP on this address, such as :8080, and linked under the public URL.
+	// Calendar apps fetch feeds from their own servers, so the URL must be reachable from the internet.
+	calendarAddr := os.Getenv("CALENDAR_ADDR")
+	calendarURL := os.Getenv("CALENDAR_URL")
+	if calendarAddr != "" && calendarURL == "" {
+		log.Fatal("CALENDAR_URL must be set to the public address of the calendar server when CALENDAR_ADDR is set")
+	}
+
 	// Initialize PostgresDB (you can swap this out with other DB types later)
 	database, err := gossiper.NewDB(
 		gossiper.PostgresDB,
 		dsn,
 		true,
-		[]any{taskEnt.Task{}},
+		[]any{
+			taskEnt.Task{},
+			taskEnt.RoleCursor{},
+			taskEnt.Tag{},
+			taskEnt.ChecklistItem{},
+			taskEnt.Comment{},
+			taskEnt.Watcher{},
+			guildEnt.Settings{},
+			notifyEnt.Preference{},
+			notifyEnt.QuietHours{},
+			notifyEnt.Pending{},
+			digestEnt.Subscription{},
+			digestEnt.Run{},
+			userEnt.Settings{},
+			trackEnt.Entry{},
+			milestoneEnt.Milestone{},
+			projectEnt.Project{},
+			projectEnt.ChannelBinding{},
+			priorityEnt.Level{},
+			workflowEnt.State{},
+			workflowEnt.Transition{},
+			fieldEnt.Definition{},
+			fieldEnt.Value{},
+			templateEnt.Template{},
+			viewEnt.View{},
+			viewEnt.Pin{},
+			importerEnt.Mapping{},
+			calendarEnt.Feed{},
+		},
 	)
 	if err != nil {
 		log.Fatalf("Failed to create database instance: %v", err)
 	}
 
-	taskService := svc.NewTaskService(database)
-	taskController := ctrl.NewTaskController(taskService)
+	guildService := guildSvc.NewGuildService(database)
+	guildController := guildCtrl.NewGuildController(guildService)
+
+	priorityService := prioritySvc.NewPriorityService(database)
+	priorityController := priorityCtrl.NewPriorityController(priorityService)
+
+	workflowService := workflowSvc.NewWorkflowService(database)
+	workflowController := workflowCtrl.NewWorkflowController(workflowService)
+
+	taskService := svc.NewTaskService(database, workflowService, priorityService)
+	taskController := ctrl.NewTaskController(taskService, guildService, priorityService)
+
+	userService := userSvc.NewUserService(database)
+	userController := userCtrl.NewUserController(userService, guildService)
+
+	notifyService := notifySvc.NewNotifyService(database)
+	notifyController := notifyCtrl.NewNotifyController(notifyService)
+	notifier := discord.NewNotifier(notifyController, userController)
+
+	digestService := digestSvc.NewDigestService(database)
+	digestController := digestCtrl.NewDigestController(digestService)
+
+	trackService := trackSvc.NewTrackService(database)
+	trackController := trackCtrl.NewTrackController(trackService)
+
+	statsService := statsSvc.NewStatsService(database)
+	statsController := statsCtrl.NewStatsController(statsService)
+
+	milestoneService := milestoneSvc.NewMilestoneService(database)
+	milestoneController := milestoneCtrl.NewMilestoneController(milestoneService)
+
+	projectService := projectSvc.NewProjectService(database)
+	projectController := projectCtrl.NewProjectController(projectService)
+
+	fieldService := fieldSvc.NewFieldService(database)
+	fieldController := fieldCtrl.NewFieldController(fieldService)
+
+	templateService := templateSvc.NewTemplateService(database)
+	templateController := templateCtrl.NewTemplateController(templateService)
+
+	viewService := viewSvc.NewViewService(database)
+	viewController := viewCtrl.NewViewController(viewService)
+
+	exportService := exportSvc.NewExportService(database)
+	exportController := exportCtrl.NewExportController(exportService)
+
+	importService := importerSvc.NewImportService(database, taskService, workflowService, priorityService)
+	importController := importerCtrl.NewImportController(importService)
+
+	calendarService := calendarSvc.NewCalendarService(database)
+	calendarController := calendarCtrl.NewCalendarController(calendarService)
+
+	// Full-text search needs columns and indexes that AutoMigrate cannot express
+	searchService := searchSvc.NewSearchService(database)
+	if err := searchService.Migrate(); err != nil {
+		log.Fatalf("Failed to migrate search: %v", err)
+	}
+	searchController := searchCtrl.NewSearchController(searchService)
 
 	// Create command handler
-	commandHandler := discord.NewCommandHandler(*taskController)
+	commandHandler := discord.NewCommandHandler(*taskController, guildController, notifyController, digestController, userController, trackController, statsController, milestoneController, projectController, priorityController, workflowController, fieldController, templateController, searchController, viewController, exportController, importController, calendarController, notifier)
+
+	if calendarAddr != "" {
+		commandHandler.EnableCalendar(calendarURL)
+	}
 
 	// Create and start the bot
 	bot, err := discord.NewBot(token, commandHandler)
@@ -54,11 +210,27 @@ func main() {
 		log.Fatalf("Failed to create bot: %v", err)
 	}
 
+	// Previewing task references needs the privileged Message Content Intent
+	if os.Getenv("TASK_LINKS_ENABLED") == "true" {
+		bot.EnableTaskLinks()
+	}
+
 	err = bot.Start()
 	if err != nil {
 		log.Fatalf("Failed to start bot: %v", err)
 	}
 
+	// Start periodic jobs such as digests
+	scheduler := discord.NewScheduler(bot.Session, digestController, guildController, userController, trackController, taskController, workflowController, viewController, idleLimit)
+	scheduler.Start()
+
+	// The bot has no HTTP listener unless calendar feeds are enabled
+	var calendarServer *web.Server
+	if calendarAddr != "" {
+		calendarServer = web.NewServer(calendarAddr, calendarController)
+		calendarServer.Start()
+	}
+
 	// Wait for termination signal to gracefully shut down the bot
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
@@ -66,5 +238,9 @@ func main() {
 	<-stop // Block until a termination signal is received
 
 	log.Println("Shutting down the bot...")
+	scheduler.Stop()
+	if calendarServer != nil {
+		calendarServer.Stop()
+	}
 	bot.Stop()
 }
@@ -6,6 +6,7 @@ require (
 	github.com/bwmarrin/discordgo v0.28.1
 	github.com/joho/godotenv v1.5.1
 	github.com/pieceowater-dev/lotof.lib.gossiper/v2 v2.0.6
+	golang.org/x/image v0.18.0
 	gorm.io/gorm v1.25.12
 )
 
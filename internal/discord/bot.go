@@ -25,6 +25,13 @@ func NewBot(token string, handler *CommandHandler) (*Bot, error) {
 	}, nil
 }
 
+// EnableTaskLinks listens to chat messages to preview the tasks they reference.
+// It needs the privileged Message Content Intent enabled in the Developer Portal.
+func (b *Bot) EnableTaskLinks() {
+	b.Session.Identify.Intents = discordgo.IntentsAllWithoutPrivileged | discordgo.IntentMessageContent
+	b.Session.AddHandler(b.CommandHandler.HandleMessage)
+}
+
 // Start starts the bot
 func (b *Bot) Start() error {
 	// Add the command handler
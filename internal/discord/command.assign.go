@@ -0,0 +1,174 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"slices"
+	guildEnt "taskchord/internal/pkg/guild/ent"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+// createRoleTask creates a task for a role and announces who picked it up. Failures are
+// answered here and returned, so callers only need to know whether the task exists.
+func (h *CommandHandler) createRoleTask(s *discordgo.Session, i *discordgo.InteractionCreate, title, description, priority, roleID string, dueAt *time.Time, estimate int, milestoneID, projectID *uint, tags, checklist []string) (taskEnt.Task, error) {
+	userID := i.Member.User.ID
+	guildID := i.GuildID
+
+	members, err := roleMembers(s, guildID, roleID)
+	if err != nil {
+		log.Printf("Error fetching members of role %s: %v", roleID, err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the members of that role. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return taskEnt.Task{}, err
+	}
+
+	task, err := h.taskController.CreateTaskForRole(guildID, userID, title, description, priority, roleID, dueAt, estimate, milestoneID, projectID, tags, checklist, members)
+	if err != nil {
+		log.Printf("Error creating task: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to create task. Make sure the priority is on the scale of the server (see /priority list), there are at most 10 tags and 25 checklist items, or try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return taskEnt.Task{}, err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color:       0x00FF00,
+		Description: fmt.Sprintf("Task **%s %s** successfully created!", task.Reference(), title),
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed}, // Non-ephemeral message
+		},
+	})
+
+	// Either notify the picked member or invite the whole role to claim the task
+	if task.ExecutorID == "" {
+		message := fmt.Sprintf("<@&%s>, task **%s %s** was added to your queue by <@%s>. Use `/claim id: %s` to take it.", roleID, task.Reference(), title, userID, task.Reference())
+		h.notifier.Broadcast(s, i.ChannelID, message)
+	} else if task.ExecutorID != userID {
+		message := fmt.Sprintf("task **%s %s** was assigned to you via <@&%s> by <@%s>", task.Reference(), title, roleID, userID)
+		h.notifier.Notify(s, guildID, i.ChannelID, task.ExecutorID, notifyEnt.Assigned, message)
+	}
+	return task, nil
+}
+
+func (h *CommandHandler) handleClaimCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+	id := i.ApplicationCommandData().Options[0].StringValue()
+
+	task, err := h.taskController.ClaimTask(guildID, userID, id, i.Member.Roles)
+	if err != nil {
+		log.Printf("Error claiming task: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to claim task. Make sure it is still unclaimed and queued for one of your roles.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Task **%s %s** was claimed by <@%s>", task.Reference(), task.Title, userID),
+		},
+	})
+}
+
+func (h *CommandHandler) handleAssignmentCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	strategy := i.ApplicationCommandData().Options[0].StringValue()
+
+	err := h.guildController.SetAssignStrategy(guildID, strategy)
+	if err != nil {
+		log.Printf("Error updating assignment strategy: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to update the assignment strategy. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Tasks assigned to a role will now use the **%s** strategy.", guildEnt.AssignStrategy(strategy)),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleAutolinkCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	enabled := i.ApplicationCommandData().Options[0].BoolValue()
+
+	err := h.guildController.SetLinkTasks(guildID, enabled)
+	if err != nil {
+		log.Printf("Error updating task link setting: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to update the task link setting. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	content := "Task references such as #42 or BE-42 in messages will no longer be previewed."
+	if enabled {
+		content = "Task references such as #42 or BE-42 in messages will now be previewed."
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// roleMembers lists the IDs of all non-bot members of a guild holding the given role
+func roleMembers(s *discordgo.Session, guildID, roleID string) ([]string, error) {
+	var members []string
+	after := ""
+
+	// Members are returned in pages of at most 1000
+	for {
+		page, err := s.GuildMembers(guildID, after, 1000)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range page {
+			if !member.User.Bot && slices.Contains(member.Roles, roleID) {
+				members = append(members, member.User.ID)
+			}
+		}
+
+		if len(page) < 1000 {
+			return members, nil
+		}
+		after = page[len(page)-1].User.ID
+	}
+}
@@ -0,0 +1,47 @@
+package discord
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleAutocomplete suggests values for the option the user is currently typing
+func (h *CommandHandler) handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	focused := focusedOption(i.ApplicationCommandData().Options)
+	if focused == nil {
+		return
+	}
+
+	switch focused.Name {
+	case "zone":
+		handleTimezoneAutocomplete(s, i, focused.StringValue())
+	case "milestone":
+		h.handleMilestoneAutocomplete(s, i, focused.StringValue())
+	case "project":
+		h.handleProjectAutocomplete(s, i, focused.StringValue())
+	case "priority", "level":
+		h.handlePriorityAutocomplete(s, i, focused.StringValue())
+	case "status", "from", "to":
+		h.handleStatusAutocomplete(s, i, focused.StringValue())
+	case "field":
+		h.handleFieldAutocomplete(s, i, focused.StringValue())
+	case "value":
+		h.handleFieldValueAutocomplete(s, i, focused.StringValue())
+	case "template":
+		h.handleTemplateAutocomplete(s, i, focused.StringValue())
+	case "view":
+		h.handleViewAutocomplete(s, i, focused.StringValue())
+	}
+}
+
+// focusedOption finds the option being typed, looking inside subcommands
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Focused {
+			return opt
+		}
+		if focused := focusedOption(opt.Options); focused != nil {
+			return focused
+		}
+	}
+	return nil
+}
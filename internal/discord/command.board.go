@@ -0,0 +1,137 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	workflowEnt "taskchord/internal/pkg/workflow/ent"
+	"time"
+)
+
+// boardDoneDays is how long completed tasks stay on the board
+const boardDoneDays = 7
+
+func (h *CommandHandler) handleBoardCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+
+	var name, query string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "project":
+			name = opt.StringValue()
+		case "query":
+			query = opt.StringValue()
+		}
+	}
+
+	// Relative dates of the query count from the member's current date
+	today := memberToday(h.userController, guildID, i.Member.User.ID)
+	filter, err := h.taskController.CompileQuery(guildID, i.Member.User.ID, query, today)
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Failed to fetch the board. The query is not valid: %v.", err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Without a project option the board follows the channel's project, if any.
+	// Managers see every task; everyone else the tasks they wrote, execute or could claim.
+	project, err := h.resolveProject(s, guildID, i.ChannelID, name)
+	var tasks []taskEnt.Task
+	if err == nil {
+		var projectID *uint
+		if project != nil {
+			projectID = &project.ID
+		}
+		manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+		tasks, err = h.taskController.GetBoard(guildID, i.Member.User.ID, i.Member.Roles, manager, projectID, time.Now().AddDate(0, 0, -boardDoneDays), filter)
+	}
+	if err != nil {
+		log.Printf("Error fetching board: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the board. Make sure the project exists.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Columns follow the states of the guild's workflow
+	workflow, err := h.workflowController.GetWorkflow(guildID)
+	if err != nil {
+		log.Printf("Error fetching workflow: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the board. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	title := "Board:"
+	if project != nil {
+		title = fmt.Sprintf("Board of %s:", project.Name)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{boardEmbed(title, tasks, workflow, s, guildID)},
+		},
+	})
+}
+
+// boardEmbed renders tasks as one column per state of the workflow
+func boardEmbed(title string, tasks []taskEnt.Task, workflow workflowEnt.Workflow, s *discordgo.Session, guildID string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:  title,
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+
+	for _, state := range workflow.States {
+		name := state.Name
+		if state.Terminal {
+			name = fmt.Sprintf("%s (last %d days)", state.Name, boardDoneDays)
+		}
+
+		var lines []string
+		for _, task := range tasks {
+			if string(task.Status) != state.Name {
+				continue
+			}
+			line := fmt.Sprintf("**%s** %s", task.Reference(), task.Title)
+			if task.ExecutorID != "" {
+				line += " · " + GetNicknameFromIDWithCache(task.ExecutorID, s, guildID)
+			} else {
+				line += fmt.Sprintf(" · <@&%s>", task.RoleID)
+			}
+			if task.DueAt != nil && task.CompletedAt == nil {
+				line += " · due " + formatDueDate(task.DueAt)
+			}
+			lines = append(lines, line)
+		}
+
+		value := "Nothing here!"
+		if len(lines) > 0 {
+			value = truncateField(strings.Join(lines, "\n"))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%s (%d)", name, len(lines)),
+			Value:  value,
+			Inline: true,
+		})
+	}
+
+	return embed
+}
@@ -0,0 +1,244 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+// bulkPreviewLines is how many tasks a bulk preview lists before summing up the rest
+const bulkPreviewLines = 15
+
+// bulkOperation is a previewed bulk change waiting for the member to confirm it
+type bulkOperation struct {
+	change  taskEnt.BulkChange
+	ids     []uint
+	manager bool
+}
+
+func (h *CommandHandler) handleBulkCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Member.User.ID
+	var list, query, tags string
+	var change taskEnt.BulkChange
+
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "action":
+			change.Action = taskEnt.BulkAction(opt.StringValue())
+		case "tasks":
+			list = opt.StringValue()
+		case "query":
+			query = opt.StringValue()
+		case "executor":
+			change.ExecutorID = opt.UserValue(nil).ID
+		case "priority":
+			change.Priority = taskEnt.Priority(opt.StringValue())
+		case "tags":
+			tags = opt.StringValue()
+		}
+	}
+
+	// Tags prefixed with a dash are removed, the others added
+	for _, tag := range splitList(tags, ",") {
+		if strings.HasPrefix(tag, "-") {
+			change.RemoveTags = append(change.RemoveTags, strings.TrimPrefix(tag, "-"))
+		} else {
+			change.AddTags = append(change.AddTags, tag)
+		}
+	}
+
+	// Managers may change every task of the server, everyone else only the tasks they created
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	today := memberToday(h.userController, i.GuildID, userID)
+
+	tasks, err := h.taskController.SelectBulkTasks(i.GuildID, userID, manager, list, query, today)
+	var ids []uint
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	var changed []taskEnt.Task
+	if err == nil {
+		changed, err = h.taskController.BulkApply(i.GuildID, userID, manager, ids, change, true)
+	}
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("The bulk operation cannot be done: %v.", err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	h.bulkOperations.put(i.ID, userID, bulkOperation{change: change, ids: ids, manager: manager}, time.Now())
+
+	confirmStyle := discordgo.SuccessButton
+	if change.Action == taskEnt.Delete {
+		confirmStyle = discordgo.DangerButton
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("This is a dry run; nothing was changed yet. Confirm to %s %d tasks.", change.Action, len(tasks)),
+			Embeds:  []*discordgo.MessageEmbed{bulkPreviewEmbed(change, tasks, changed)},
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    fmt.Sprintf("%s %d tasks", bulkVerb(change.Action), len(tasks)),
+						Style:    confirmStyle,
+						CustomID: "bulk:confirm:" + i.ID,
+					},
+					discordgo.Button{
+						Label:    "Cancel",
+						Style:    discordgo.SecondaryButton,
+						CustomID: "bulk:cancel:" + i.ID,
+					},
+				}},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// handleBulkComponent handles the buttons under a bulk preview. Their custom IDs are
+// bulk:confirm:<operation> and bulk:cancel:<operation>.
+func (h *CommandHandler) handleBulkComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) < 3 {
+		return
+	}
+	userID := i.Member.User.ID
+
+	operation, found := h.bulkOperations.take(parts[2], userID, time.Now())
+	if !found {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "This preview expired or was already used. Run /bulk again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	content := "The bulk operation was cancelled; nothing was changed."
+	if parts[1] == "confirm" {
+		tasks, err := h.taskController.BulkApply(i.GuildID, userID, operation.manager, operation.ids, operation.change, false)
+		if err != nil {
+			log.Printf("Error applying bulk operation: %v", err)
+			content = fmt.Sprintf("The bulk operation failed and nothing was changed: %v.", err)
+		} else {
+			content = fmt.Sprintf("%s %d tasks.", bulkPastTense(operation.change.Action), len(tasks))
+			h.notifyBulkAssignee(s, i, operation.change, tasks)
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
+
+// notifyBulkAssignee tells the new executor of reassigned tasks about them in a single notification
+func (h *CommandHandler) notifyBulkAssignee(s *discordgo.Session, i *discordgo.InteractionCreate, change taskEnt.BulkChange, tasks []taskEnt.Task) {
+	userID := i.Member.User.ID
+	if change.Action != taskEnt.Reassign || change.ExecutorID == userID {
+		return
+	}
+
+	var references []string
+	for _, task := range tasks {
+		references = append(references, task.Reference())
+	}
+	message := fmt.Sprintf("%d tasks were assigned to you by <@%s>: %s", len(tasks), userID, strings.Join(references, ", "))
+	h.notifier.Notify(s, i.GuildID, i.ChannelID, change.ExecutorID, notifyEnt.Assigned, message)
+}
+
+// bulkPreviewEmbed lists the tasks of a bulk operation and how each of them changes
+func bulkPreviewEmbed(change taskEnt.BulkChange, before, after []taskEnt.Task) *discordgo.MessageEmbed {
+	changed := make(map[uint]taskEnt.Task)
+	for _, task := range after {
+		changed[task.ID] = task
+	}
+
+	var lines []string
+	for index, task := range before {
+		if index == bulkPreviewLines {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(before)-bulkPreviewLines))
+			break
+		}
+		line := "**" + task.Reference() + " " + task.Title + "**"
+		result := changed[task.ID]
+		switch change.Action {
+		case taskEnt.Close:
+			line += fmt.Sprintf(": %s → %s", task.Status, result.Status)
+		case taskEnt.Reassign:
+			from := "nobody"
+			if task.ExecutorID != "" {
+				from = "<@" + task.ExecutorID + ">"
+			}
+			line += fmt.Sprintf(": %s → <@%s>", from, result.ExecutorID)
+		case taskEnt.Reprioritize:
+			line += fmt.Sprintf(": %s → %s", task.Priority, result.Priority)
+		case taskEnt.Retag:
+			line += fmt.Sprintf(": %s → %s", tagList(task.Tags), tagList(result.Tags))
+		}
+		lines = append(lines, line)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s %d tasks:", bulkVerb(change.Action), len(before)),
+		Description: strings.Join(lines, "\n"),
+		Color:       0x00FF00, // Green color
+	}
+}
+
+// tagList renders the tags of a task for a preview
+func tagList(tags []taskEnt.Tag) string {
+	if len(tags) == 0 {
+		return "no tags"
+	}
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// bulkVerb names a bulk action for a button or a title
+func bulkVerb(action taskEnt.BulkAction) string {
+	switch action {
+	case taskEnt.Reprioritize:
+		return "Reprioritize"
+	case taskEnt.Reassign:
+		return "Reassign"
+	case taskEnt.Retag:
+		return "Retag"
+	case taskEnt.Delete:
+		return "Delete"
+	}
+	return "Close"
+}
+
+// bulkPastTense reports a finished bulk action
+func bulkPastTense(action taskEnt.BulkAction) string {
+	switch action {
+	case taskEnt.Reprioritize:
+		return "Reprioritized"
+	case taskEnt.Reassign:
+		return "Reassigned"
+	case taskEnt.Retag:
+		return "Retagged"
+	case taskEnt.Delete:
+		return "Deleted"
+	}
+	return "Closed"
+}
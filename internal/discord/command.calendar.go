@@ -0,0 +1,128 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+)
+
+// EnableCalendar lets /calendar hand out feed links. The base URL is where the calendar server
+// is reachable from the outside, such as https://bot.example.com.
+func (h *CommandHandler) EnableCalendar(baseURL string) {
+	h.calendarURL = strings.TrimSuffix(baseURL, "/")
+}
+
+func (h *CommandHandler) handleCalendarCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if h.calendarURL == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Calendar feeds are not enabled on this bot.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	switch subcommand.Name {
+	case "link":
+		h.handleCalendarLink(s, i, subcommand.Options)
+	case "reset":
+		h.handleCalendarReset(s, i)
+	}
+}
+
+func (h *CommandHandler) handleCalendarLink(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var projectName string
+	for _, opt := range options {
+		if opt.Name == "project" {
+			projectName = opt.StringValue()
+		}
+	}
+
+	// A project feed shows every task of the project to anyone holding the link, without asking who
+	// they are, so only managers, who see every task anyway, get one
+	if projectName != "" && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission for the calendar of a project. Leave out the project for a calendar of your own tasks.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Without a project the feed has the member's own tasks
+	var projectID *uint
+	if projectName != "" {
+		project, err := h.projectController.GetProject(i.GuildID, projectName)
+		if err != nil {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: fmt.Sprintf("Project %s does not exist.", projectName),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		projectID = &project.ID
+	}
+
+	feed, err := h.calendarController.GetFeed(i.GuildID, i.Member.User.ID, projectID)
+	if err != nil {
+		log.Printf("Error getting calendar feed: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to create the calendar link. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	what := "your tasks"
+	if projectName != "" {
+		what = "the tasks of project " + projectName
+	}
+	// The link works without logging in, so it is only ever shown to its owner
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Subscribe to this link in Google Calendar or Outlook to see the due dates of %s and the milestones of the server:\n%s/calendar/%s.ics\nAnyone with the link can read the calendar; use `/calendar reset` to revoke it.", what, h.calendarURL, feed.Token),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleCalendarReset(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	count, err := h.calendarController.ResetFeeds(i.GuildID, i.Member.User.ID)
+	if err != nil {
+		log.Printf("Error resetting calendar feeds: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to reset the calendar links. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	content := "You have no calendar links to reset."
+	if count > 0 {
+		content = fmt.Sprintf("Revoked %d calendar links. Use `/calendar link` to get new ones.", count)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,183 @@
+package discord
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"taskchord/internal/pkg/chart"
+	statsEnt "taskchord/internal/pkg/stats/ent"
+	"time"
+)
+
+func (h *CommandHandler) handleChartCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	// Days are those of the member asking for the chart
+	location := h.userController.GetLocation(guildID, i.Member.User.ID)
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	var name string
+	var image *bytes.Buffer
+	var err error
+	switch subcommand.Name {
+	case "burndown":
+		// The period defaults to the current week, or follows the milestone
+		from := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		to := from.AddDate(0, 0, 6)
+		var milestone string
+		for _, opt := range subcommand.Options {
+			switch opt.Name {
+			case "from":
+				from, err = parseReportDate(opt.StringValue(), location)
+			case "to":
+				to, err = parseReportDate(opt.StringValue(), location)
+			case "milestone":
+				milestone = opt.StringValue()
+			}
+			if err != nil {
+				break
+			}
+		}
+		if err == nil {
+			name = "burndown.png"
+			image, err = h.burndownChart(guildID, milestone, from, to, today, location)
+		}
+	case "flow":
+		weeks := 4
+		if len(subcommand.Options) > 0 {
+			weeks = int(subcommand.Options[0].IntValue())
+		}
+		name = "flow.png"
+		image, err = h.flowChart(guildID, today.AddDate(0, 0, 1-7*weeks), today, location)
+	case "workload":
+		name = "workload.png"
+		image, err = h.workloadChart(s, guildID)
+	}
+
+	if err != nil {
+		log.Printf("Error rendering chart: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to render the chart. Dates must look like 2024-12-31, today or -7d, the period must span two days to a year, and milestones must exist.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Color: 0x00FF00, // Green color
+				Image: &discordgo.MessageEmbedImage{URL: "attachment://" + name},
+			}},
+			Files: []*discordgo.File{{Name: name, ContentType: "image/png", Reader: image}},
+		},
+	})
+}
+
+// burndownChart draws the remaining tasks of a milestone, or else those due in a period, against an ideal line
+func (h *CommandHandler) burndownChart(guildID, milestoneName string, from, to, today time.Time, location *time.Location) (*bytes.Buffer, error) {
+	var flow []statsEnt.DayFlow
+	var title string
+	if milestoneName != "" {
+		milestone, err := h.milestoneController.GetMilestone(guildID, milestoneName)
+		if err != nil {
+			return nil, err
+		}
+
+		// Milestone dates are calendar days, drawn in the local time zone
+		from = time.Date(milestone.StartDate.Year(), milestone.StartDate.Month(), milestone.StartDate.Day(), 0, 0, 0, 0, location)
+		to = time.Date(milestone.EndDate.Year(), milestone.EndDate.Month(), milestone.EndDate.Day(), 0, 0, 0, 0, location)
+		flow, err = h.statsController.GetMilestoneBurndown(guildID, milestone.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		title = fmt.Sprintf("Burndown of %s", milestone.Name)
+	} else {
+		var err error
+		flow, err = h.statsController.GetBurndown(guildID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		title = fmt.Sprintf("Burndown of tasks due %s to %s", from.Format(dueDateLayout), to.Format(dueDateLayout))
+	}
+
+	labels := dayLabels(flow, location)
+	var remaining, ideal []float64
+	for index, day := range flow {
+		// The future has no data yet, only the ideal line reaches the end
+		if !day.Day.After(today) {
+			remaining = append(remaining, float64(day.Open))
+		}
+		if len(flow) > 1 {
+			ideal = append(ideal, float64(flow[0].Open+flow[0].Done)*float64(len(flow)-1-index)/float64(len(flow)-1))
+		}
+	}
+
+	return chart.Line(title, labels, []chart.Series{
+		{Name: "Remaining", Values: remaining, Color: chart.Palette[0]},
+		{Name: "Ideal", Values: ideal, Color: chart.Palette[1], Dashed: true},
+	})
+}
+
+// flowChart draws how many tasks were done and open at the end of every day
+func (h *CommandHandler) flowChart(guildID string, from, to time.Time, location *time.Location) (*bytes.Buffer, error) {
+	flow, err := h.statsController.GetDailyFlow(guildID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var done, open []float64
+	for _, day := range flow {
+		done = append(done, float64(day.Done))
+		open = append(open, float64(day.Open))
+	}
+
+	// Done tasks are named after the terminal state of the guild's workflow
+	workflow, err := h.workflowController.GetWorkflow(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf("Cumulative flow from %s to %s", from.Format(dueDateLayout), to.Format(dueDateLayout))
+	return chart.StackedArea(title, dayLabels(flow, location), []chart.Series{
+		{Name: workflow.Terminal().Name, Values: done, Color: chart.Palette[0]},
+		{Name: "Open", Values: open, Color: chart.Palette[1]},
+	})
+}
+
+// workloadChart draws the open tasks of every executor
+func (h *CommandHandler) workloadChart(s *discordgo.Session, guildID string) (*bytes.Buffer, error) {
+	workload, err := h.statsController.GetWorkload(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	var values []float64
+	for _, group := range workload {
+		label := "Unclaimed"
+		if group.Key != "" {
+			label = GetNicknameFromIDWithCache(group.Key, s, guildID)
+		}
+		labels = append(labels, label)
+		values = append(values, float64(group.Count))
+	}
+
+	return chart.Bar("Open tasks per executor", labels, values, chart.Palette[0])
+}
+
+// dayLabels names every day of a flow for the x axis, as seen in the given location
+func dayLabels(flow []statsEnt.DayFlow, location *time.Location) []string {
+	var labels []string
+	for _, day := range flow {
+		labels = append(labels, day.Day.In(location).Format("Jan 02"))
+	}
+	return labels
+}
@@ -0,0 +1,110 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	taskEnt "taskchord/internal/pkg/task/ent"
+)
+
+func (h *CommandHandler) handleChecklistCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	var id, text string
+	var number int
+	for _, opt := range subcommand.Options {
+		switch opt.Name {
+		case "id":
+			id = opt.StringValue()
+		case "item":
+			text = opt.StringValue()
+		case "number":
+			number = int(opt.IntValue())
+		}
+	}
+
+	// Checklists can be changed by the author and the executor of a task, and by managers
+	task, err := h.taskController.GetTask(guildID, id)
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	if err == nil && task.UserID != userID && task.ExecutorID != userID && !manager {
+		err = fmt.Errorf("task with ID %s is not yours", id)
+	}
+	if err != nil {
+		log.Printf("Error changing checklist: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to change the checklist. Make sure the task exists and you are its author or executor.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	switch subcommand.Name {
+	case "add":
+		_, err = h.taskController.AddChecklistItem(task.ID, text)
+	case "check":
+		_, err = h.taskController.ToggleChecklistItem(task.ID, number)
+	case "remove":
+		_, err = h.taskController.RemoveChecklistItem(task.ID, number)
+	}
+	if err == nil {
+		task, err = h.taskController.GetTask(guildID, id)
+	}
+	if err != nil {
+		log.Printf("Error changing checklist: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to change the checklist. Items have at most 200 characters, a checklist at most 25 items, and numbers must match an item.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Checklist of %s %s:", task.Reference(), task.Title),
+		Description: checklistLines(task.Checklist),
+		Color:       0x00FF00, // Green color
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// checklistLines renders the items of a checklist with their numbers
+func checklistLines(items []taskEnt.ChecklistItem) string {
+	if len(items) == 0 {
+		return "The checklist is empty."
+	}
+
+	var lines []string
+	for index, item := range items {
+		box := "☐"
+		if item.Done {
+			box = "☑"
+		}
+		lines = append(lines, fmt.Sprintf("%s %d. %s", box, index+1, item.Text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checklistProgress counts the done items of a checklist
+func checklistProgress(items []taskEnt.ChecklistItem) string {
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	return fmt.Sprintf("%d/%d done", done, len(items))
+}
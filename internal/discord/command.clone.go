@@ -0,0 +1,176 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+	projectEnt "taskchord/internal/pkg/project/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+)
+
+func (h *CommandHandler) handleCloneCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+
+	var id string
+	var includeChecklist, includeComments bool
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "id":
+			id = opt.StringValue()
+		case "checklist":
+			includeChecklist = opt.BoolValue()
+		case "comments":
+			includeComments = opt.BoolValue()
+		}
+	}
+
+	// A clone shows everything of the original, so members who are no managers only clone tasks they can see
+	source, err := h.taskController.GetTask(guildID, id)
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	if err == nil && !manager && !source.VisibleTo(userID, i.Member.Roles) {
+		err = fmt.Errorf("task with ID %s is not visible to user %s", id, userID)
+	}
+
+	var clone taskEnt.Task
+	if err == nil {
+		source, clone, err = h.taskController.CloneTask(guildID, userID, id, includeChecklist, includeComments)
+	}
+	if err != nil {
+		log.Printf("Error cloning task: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to clone the task. Make sure it exists and you wrote it, execute it or could claim it for one of your roles.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color:       0x00FF00,
+		Description: fmt.Sprintf("Task **%s %s** was cloned from %s!", clone.Reference(), clone.Title, source.Reference()),
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+
+	// The copy keeps the executor, who should hear about it unless they made it
+	if clone.ExecutorID != "" && clone.ExecutorID != userID {
+		message := fmt.Sprintf("task **%s %s** was assigned to you by <@%s>", clone.Reference(), clone.Title, userID)
+		h.notifier.Notify(s, guildID, i.ChannelID, clone.ExecutorID, notifyEnt.Assigned, message)
+	}
+}
+
+func (h *CommandHandler) handleMergeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+
+	var sourceID, targetID string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "source":
+			sourceID = opt.StringValue()
+		case "target":
+			targetID = opt.StringValue()
+		}
+	}
+
+	// Merging deletes the source, so it takes the author of both tasks or a manager
+	var target taskEnt.Task
+	source, err := h.taskController.GetTask(guildID, sourceID)
+	if err == nil {
+		target, err = h.taskController.GetTask(guildID, targetID)
+	}
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	if err == nil && !manager && (source.UserID != userID || target.UserID != userID) {
+		err = fmt.Errorf("tasks %s and %s are not both yours", sourceID, targetID)
+	}
+
+	if err == nil {
+		source, target, err = h.taskController.MergeTasks(guildID, sourceID, targetID)
+	}
+	if err != nil {
+		log.Printf("Error merging tasks: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to merge the tasks. Make sure both exist and differ, and that you are the author of both or have the Manage Server permission.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color:       0x00FF00,
+		Description: fmt.Sprintf("Task **%s %s** was merged into **%s %s**!", source.Reference(), source.Title, target.Reference(), target.Title),
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+}
+
+func (h *CommandHandler) handleMoveCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+
+	var id, projectName string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "id":
+			id = opt.StringValue()
+		case "project":
+			projectName = opt.StringValue()
+		}
+	}
+
+	// Tasks can be moved by their author and executor, and by managers
+	task, err := h.taskController.GetTask(guildID, id)
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	if err == nil && task.UserID != userID && task.ExecutorID != userID && !manager {
+		err = fmt.Errorf("task with ID %s is not yours", id)
+	}
+
+	// Moving to none takes the task out of its project
+	var projectID *uint
+	destination := "out of its project"
+	if err == nil && !strings.EqualFold(strings.TrimSpace(projectName), "none") {
+		var project projectEnt.Project
+		project, err = h.projectController.GetProject(guildID, projectName)
+		projectID = &project.ID
+		destination = "to project " + project.Name
+	}
+
+	previous := task.Reference()
+	if err == nil {
+		task, err = h.taskController.MoveTask(guildID, id, projectID)
+	}
+	if err != nil {
+		log.Printf("Error moving task: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to move the task. Make sure the task and the project exist, the task is not already there, and you are its author or executor.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Task %s was moved %s and is now **%s %s**.", previous, destination, task.Reference(), task.Title),
+		},
+	})
+}
@@ -0,0 +1,185 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"slices"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+)
+
+func (h *CommandHandler) handleCommentCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	var id, text string
+	for _, opt := range subcommand.Options {
+		switch opt.Name {
+		case "id":
+			id = opt.StringValue()
+		case "text":
+			text = opt.StringValue()
+		}
+	}
+
+	switch subcommand.Name {
+	case "add":
+		h.handleCommentAdd(s, i, id, text)
+	case "list":
+		h.handleCommentList(s, i, id)
+	}
+}
+
+func (h *CommandHandler) handleCommentAdd(s *discordgo.Session, i *discordgo.InteractionCreate, id, text string) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+
+	// Comments notify the task's people and show up in search, so members who are no managers only comment on tasks they can see
+	task, err := h.taskController.GetTask(guildID, id)
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	if err == nil && !manager && !task.VisibleTo(userID, i.Member.Roles) {
+		err = fmt.Errorf("task with ID %s is not visible to user %s", id, userID)
+	}
+	if err == nil {
+		_, err = h.taskController.AddComment(task.ID, userID, text)
+	}
+	if err != nil {
+		log.Printf("Error adding comment: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to add the comment. Make sure the task exists, you wrote it, execute it or could claim it for one of your roles, and the comment has at most 1000 characters.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Comment on %s %s", task.Reference(), task.Title),
+		Description: text,
+		Color:       0x00FF00, // Green color
+		Footer:      &discordgo.MessageEmbedFooter{Text: GetNicknameFromIDWithCache(userID, s, guildID)},
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+
+	// The author, the executor and the watchers of the task hear about the comment, except whoever wrote it
+	watchers, err := h.taskController.GetWatchers(task.ID)
+	if err != nil {
+		log.Printf("Error fetching watchers: %v", err)
+	}
+	recipients := []string{}
+	for _, recipient := range append([]string{task.UserID, task.ExecutorID}, watchers...) {
+		if recipient != "" && recipient != userID && !slices.Contains(recipients, recipient) {
+			recipients = append(recipients, recipient)
+		}
+	}
+	message := fmt.Sprintf("<@%s> commented on task **%s %s**", userID, task.Reference(), task.Title)
+	for _, recipient := range recipients {
+		h.notifier.Notify(s, guildID, i.ChannelID, recipient, notifyEnt.Commented, message)
+	}
+}
+
+func (h *CommandHandler) handleCommentList(s *discordgo.Session, i *discordgo.InteractionCreate, id string) {
+	userID := i.Member.User.ID
+	guildID := i.GuildID
+	location := h.userController.GetLocation(guildID, userID)
+
+	task, err := h.taskController.GetTask(guildID, id)
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	if err == nil && !manager && !task.VisibleTo(userID, i.Member.Roles) {
+		err = fmt.Errorf("task with ID %s is not visible to user %s", id, userID)
+	}
+	if err != nil {
+		log.Printf("Error fetching task: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the comments. Make sure the task exists and you wrote it, execute it or could claim it for one of your roles.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	comments, err := h.taskController.GetComments(task.ID)
+	if err != nil {
+		log.Printf("Error fetching comments: %v", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("Comments on %s %s:", task.Reference(), task.Title),
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+	if len(comments) == 0 {
+		embed.Description = "No comments yet. Add one with /comment add."
+	}
+
+	// Embeds hold at most 25 fields, so only the latest comments are listed
+	if len(comments) > 25 {
+		comments = comments[len(comments)-25:]
+	}
+	for _, comment := range comments {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s, %s", GetNicknameFromIDWithCache(comment.UserID, s, guildID), formatDateTime(comment.CreatedAt, location)),
+			Value: truncateField(comment.Content),
+		})
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleWatchCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	id := i.ApplicationCommandData().Options[0].StringValue()
+
+	// Watchers hear about every comment, so members who are no managers only start watching tasks they can see;
+	// anyone may still stop watching a task that went out of sight
+	task, err := h.taskController.GetTask(i.GuildID, id)
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	if err == nil && !manager && !task.VisibleTo(userID, i.Member.Roles) {
+		var watchers []string
+		watchers, err = h.taskController.GetWatchers(task.ID)
+		if err == nil && !slices.Contains(watchers, userID) {
+			err = fmt.Errorf("task with ID %s is not visible to user %s", id, userID)
+		}
+	}
+	var watching bool
+	if err == nil {
+		watching, err = h.taskController.ToggleWatch(task.ID, userID)
+	}
+	if err != nil {
+		log.Printf("Error watching task: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to watch the task. Make sure it exists and you wrote it, execute it or could claim it for one of your roles, or try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	content := fmt.Sprintf("You are now watching task **%s %s** and will hear about its comments.", task.Reference(), task.Title)
+	if !watching {
+		content = fmt.Sprintf("You stopped watching task **%s %s**.", task.Reference(), task.Title)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
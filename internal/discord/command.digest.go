@@ -0,0 +1,87 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	viewEnt "taskchord/internal/pkg/view/ent"
+)
+
+func (h *CommandHandler) handleDigestCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	var content string
+	var err error
+	switch subcommand.Name {
+	case "subscribe":
+		var frequency, delivery, viewName string
+		for _, opt := range subcommand.Options {
+			switch opt.Name {
+			case "frequency":
+				frequency = opt.StringValue()
+			case "delivery":
+				delivery = opt.StringValue()
+			case "view":
+				viewName = opt.StringValue()
+			}
+		}
+
+		// A saved view adds its open tasks to the digest
+		var viewID *uint
+		if viewName != "" {
+			var view viewEnt.View
+			view, err = h.viewController.GetView(guildID, userID, viewName)
+			viewID = &view.ID
+		}
+		if err == nil {
+			err = h.digestController.Subscribe(guildID, userID, frequency, delivery, viewID)
+		}
+		content = fmt.Sprintf("You are now subscribed to the %s digest.", frequency)
+		if viewID != nil {
+			content = fmt.Sprintf("You are now subscribed to the %s digest, including the open tasks of view %s.", frequency, viewName)
+		}
+	case "unsubscribe":
+		err = h.digestController.Unsubscribe(guildID, userID)
+		content = "You will no longer receive digests from this server."
+	case "schedule":
+		// Only members who can manage the guild may change where and when digests are sent
+		if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "You need the Manage Server permission to change the digest schedule.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		channelID := subcommand.Options[0].ChannelValue(nil).ID
+		hour := int(subcommand.Options[1].IntValue())
+		weekday := int(subcommand.Options[2].IntValue())
+		err = h.guildController.SetDigestSchedule(guildID, channelID, hour, weekday)
+		content = fmt.Sprintf("Digests will be posted in <#%s> at %02d:00 in each member's time zone.", channelID, hour)
+	}
+
+	if err != nil {
+		log.Printf("Error updating digest settings: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to update digest settings. Please check your input and try again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,159 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	"time"
+)
+
+// taskDraft holds the options of a /create command waiting for the member to look at likely duplicates
+type taskDraft struct {
+	title       string
+	description string
+	priority    string
+	executorID  string
+	roleID      string
+	dueAt       *time.Time
+	estimate    int
+	milestoneID *uint
+	projectID   *uint
+	tags        []string
+	checklist   []string
+
+	interaction *discordgo.Interaction // The /create command, whose reply carries the buttons
+}
+
+// offerDuplicates replies with the open tasks resembling a draft, if there are any, and reports whether it did.
+// The draft is kept until the member creates the task anyway or watches one of the duplicates.
+func (h *CommandHandler) offerDuplicates(s *discordgo.Session, i *discordgo.InteractionCreate, draft taskDraft) bool {
+	// Members who are no managers are only offered tasks they can see, since the reply shows and watches them
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	duplicates, err := h.taskController.FindDuplicates(i.GuildID, i.Member.User.ID, i.Member.Roles, manager, draft.title, draft.description)
+	if err != nil {
+		// Failing to look for duplicates should never keep a task from being created
+		log.Printf("Error finding duplicates: %v", err)
+		return false
+	}
+	if len(duplicates) == 0 {
+		return false
+	}
+
+	draft.interaction = i.Interaction
+	h.drafts.put(i.ID, i.Member.User.ID, draft, time.Now())
+
+	var lines []string
+	buttons := []discordgo.MessageComponent{
+		discordgo.Button{
+			Label:    "Create anyway",
+			Style:    discordgo.PrimaryButton,
+			CustomID: "duplicate:create:" + i.ID,
+		},
+	}
+	for _, duplicate := range duplicates {
+		task := duplicate.Task
+		lines = append(lines, fmt.Sprintf("**%s %s** (%s, %.0f%% similar)", task.Reference(), task.Title, task.Status, duplicate.Score*100))
+		buttons = append(buttons, discordgo.Button{
+			Label:    "Watch " + task.Reference(),
+			Style:    discordgo.SecondaryButton,
+			CustomID: "duplicate:watch:" + i.ID + ":" + task.Reference(),
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Likely duplicates:",
+		Description: truncateField(strings.Join(lines, "\n")),
+		Color:       0x00FF00, // Green color
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:    fmt.Sprintf("**%s** looks like tasks that are already open. Watch one of them to hear about it, or create your task anyway.", draft.title),
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+	return true
+}
+
+// handleDuplicateComponent handles the buttons under likely duplicates. Their custom IDs are
+// duplicate:create:<draft> and duplicate:watch:<draft>:<task reference>.
+func (h *CommandHandler) handleDuplicateComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) < 3 {
+		return
+	}
+	userID := i.Member.User.ID
+
+	draft, found := h.drafts.take(parts[2], userID, time.Now())
+	if !found {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "This draft expired or was already used. Run /create again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	switch parts[1] {
+	case "create":
+		task, err := h.createTask(s, i, draft)
+		if err != nil {
+			// The failure was answered; the buttons stay usable for another try
+			h.drafts.put(parts[2], userID, draft, time.Now())
+			return
+		}
+
+		// The buttons are spent once the task exists
+		content := fmt.Sprintf("You created **%s %s** anyway.", task.Reference(), task.Title)
+		_, err = s.InteractionResponseEdit(draft.interaction, &discordgo.WebhookEdit{
+			Content:    &content,
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+		if err != nil {
+			log.Printf("Error removing duplicate buttons: %v", err)
+		}
+	case "watch":
+		if len(parts) < 4 {
+			return
+		}
+		// The custom ID comes from the client, so the task is checked again like /watch does
+		task, err := h.taskController.GetTask(i.GuildID, parts[3])
+		manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+		if err == nil && !manager && !task.VisibleTo(userID, i.Member.Roles) {
+			err = fmt.Errorf("task with ID %s is not visible to user %s", parts[3], userID)
+		}
+		if err == nil {
+			err = h.taskController.WatchTask(task.ID, userID)
+		}
+		if err != nil {
+			log.Printf("Error watching task: %v", err)
+
+			// Give the member another chance at the same draft
+			h.drafts.put(parts[2], userID, draft, time.Now())
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Failed to watch the task. It may have been deleted or be out of your sight; try another one or create your task anyway.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    fmt.Sprintf("You are now watching task **%s %s** and will hear about its comments. No new task was created.", task.Reference(), task.Title),
+				Embeds:     []*discordgo.MessageEmbed{},
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package discord
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"gorm.io/gorm"
+	"log"
+	"strconv"
+	"strings"
+	exportEnt "taskchord/internal/pkg/export/ent"
+	"time"
+)
+
+// uploadLimit keeps attachments below the smallest upload limit Discord applies to bots
+const uploadLimit = 8 << 20
+
+func (h *CommandHandler) handleExportCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Member.User.ID
+	format := exportEnt.JSON
+	var query string
+
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "format":
+			format = exportEnt.Format(opt.StringValue())
+		case "query":
+			query = opt.StringValue()
+		}
+	}
+
+	var filters []func(*gorm.DB) *gorm.DB
+	if query != "" {
+		today := memberToday(h.userController, i.GuildID, userID)
+		filter, err := h.taskController.CompileQuery(i.GuildID, userID, query, today)
+		if err != nil {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: fmt.Sprintf("The query is not valid: %v.", err),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		filters = append(filters, filter)
+	}
+
+	// Collecting a large guild can take longer than Discord waits for a reply, so the reply comes later
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		log.Printf("Error deferring export: %v", err)
+		return
+	}
+
+	// Managers export every task of the server, everyone else the tasks visible to them
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	document, err := h.exportController.Export(i.GuildID, userID, i.Member.Roles, manager, filters...)
+	var files []*discordgo.File
+	if err == nil {
+		files, err = exportFiles(document, format)
+	}
+	if err != nil {
+		log.Printf("Error exporting tasks: %v", err)
+		content := "Failed to export the tasks. Please try again later."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+		return
+	}
+
+	// Every part goes in a message of its own to stay within the upload limit
+	content := fmt.Sprintf("Exported %d tasks.", len(document.Tasks))
+	if len(files) > 1 {
+		content = fmt.Sprintf("Exported %d tasks. The zipped export is split into %d parts; join them in order before unzipping.", len(document.Tasks), len(files))
+	}
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content, Files: files[:1]}); err != nil {
+		log.Printf("Error sending export: %v", err)
+		return
+	}
+	for _, file := range files[1:] {
+		_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Files: []*discordgo.File{file},
+			Flags: discordgo.MessageFlagsEphemeral,
+		})
+		if err != nil {
+			log.Printf("Error sending export part %s: %v", file.Name, err)
+			return
+		}
+	}
+}
+
+// exportFiles writes an export in a format and packs it into attachments. Exports above the upload
+// limit are zipped, and zips still above it are split into parts of the limit.
+func exportFiles(document exportEnt.Document, format exportEnt.Format) ([]*discordgo.File, error) {
+	var data []byte
+	var err error
+	var extension, contentType string
+	switch format {
+	case exportEnt.CSV:
+		data, err = exportCSV(document)
+		extension, contentType = "csv", "text/csv"
+	case exportEnt.Markdown:
+		data = exportMarkdown(document)
+		extension, contentType = "md", "text/markdown"
+	default:
+		data, err = json.MarshalIndent(document, "", "  ")
+		extension, contentType = "json", "application/json"
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("tasks-%s-%s.%s", document.GuildID, document.ExportedAt.Format(dueDateLayout), extension)
+	if len(data) <= uploadLimit {
+		return []*discordgo.File{{Name: name, ContentType: contentType, Reader: bytes.NewReader(data)}}, nil
+	}
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	entry, err := writer.Create(name)
+	if err == nil {
+		_, err = entry.Write(data)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	zipped := archive.Bytes()
+	if len(zipped) <= uploadLimit {
+		return []*discordgo.File{{Name: name + ".zip", ContentType: "application/zip", Reader: bytes.NewReader(zipped)}}, nil
+	}
+
+	var files []*discordgo.File
+	for part := 0; part*uploadLimit < len(zipped); part++ {
+		end := min((part+1)*uploadLimit, len(zipped))
+		files = append(files, &discordgo.File{
+			Name:        fmt.Sprintf("%s.zip.%03d", name, part+1),
+			ContentType: "application/octet-stream",
+			Reader:      bytes.NewReader(zipped[part*uploadLimit : end]),
+		})
+	}
+	return files, nil
+}
+
+// exportCSV writes one row per task, with a column per custom field. Checklists, comments and history only go into JSON.
+func exportCSV(document exportEnt.Document) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	header := []string{"number", "reference", "title", "description", "status", "priority", "author_id", "executor_id", "role_id", "project", "milestone", "due", "estimate_minutes", "tags", "checklist_done", "checklist_total", "comments", "created_at", "completed_at"}
+	rows := [][]string{append(header, document.Fields...)}
+	for _, task := range document.Tasks {
+		due := ""
+		if task.DueAt != nil {
+			due = task.DueAt.Format(dueDateLayout)
+		}
+		completed := ""
+		if task.CompletedAt != nil {
+			completed = task.CompletedAt.UTC().Format(time.RFC3339)
+		}
+		done := 0
+		for _, item := range task.Checklist {
+			if item.Done {
+				done++
+			}
+		}
+
+		row := []string{
+			strconv.Itoa(task.Number),
+			task.Reference,
+			task.Title,
+			task.Description,
+			task.Status,
+			task.Priority,
+			task.AuthorID,
+			task.ExecutorID,
+			task.RoleID,
+			task.Project,
+			task.Milestone,
+			due,
+			strconv.Itoa(task.Estimate),
+			strings.Join(task.Tags, ","),
+			strconv.Itoa(done),
+			strconv.Itoa(len(task.Checklist)),
+			strconv.Itoa(len(task.Comments)),
+			task.CreatedAt.UTC().Format(time.RFC3339),
+			completed,
+		}
+		for _, field := range document.Fields {
+			row = append(row, task.Fields[field])
+		}
+		rows = append(rows, row)
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// exportMarkdown writes a readable report with a section per task, for handing to people outside Discord
+func exportMarkdown(document exportEnt.Document) []byte {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Tasks\n\nExported on %s, %d tasks.\n", document.ExportedAt.Format(dueDateLayout), len(document.Tasks))
+
+	for _, task := range document.Tasks {
+		fmt.Fprintf(&builder, "\n## %s %s\n\n", task.Reference, task.Title)
+		fmt.Fprintf(&builder, "- Status: %s\n- Priority: %s\n", task.Status, task.Priority)
+		if task.ExecutorID != "" {
+			fmt.Fprintf(&builder, "- Executor: <@%s>\n", task.ExecutorID)
+		} else if task.RoleID != "" {
+			fmt.Fprintf(&builder, "- Queued for role: <@&%s>\n", task.RoleID)
+		}
+		if task.Project != "" {
+			fmt.Fprintf(&builder, "- Project: %s\n", task.Project)
+		}
+		if task.Milestone != "" {
+			fmt.Fprintf(&builder, "- Milestone: %s\n", task.Milestone)
+		}
+		if task.DueAt != nil {
+			fmt.Fprintf(&builder, "- Due: %s\n", task.DueAt.Format(dueDateLayout))
+		}
+		if len(task.Tags) > 0 {
+			fmt.Fprintf(&builder, "- Tags: %s\n", strings.Join(task.Tags, ", "))
+		}
+		for _, field := range document.Fields {
+			if value, found := task.Fields[field]; found {
+				fmt.Fprintf(&builder, "- %s: %s\n", field, value)
+			}
+		}
+		fmt.Fprintf(&builder, "- Created: %s\n", task.CreatedAt.Format(dueDateLayout))
+		if task.CompletedAt != nil {
+			fmt.Fprintf(&builder, "- Completed: %s\n", task.CompletedAt.Format(dueDateLayout))
+		}
+
+		if task.Description != "" {
+			fmt.Fprintf(&builder, "\n%s\n", task.Description)
+		}
+		if len(task.Checklist) > 0 {
+			builder.WriteString("\n")
+			for _, item := range task.Checklist {
+				mark := " "
+				if item.Done {
+					mark = "x"
+				}
+				fmt.Fprintf(&builder, "- [%s] %s\n", mark, item.Text)
+			}
+		}
+	}
+	return []byte(builder.String())
+}
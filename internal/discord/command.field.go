@@ -0,0 +1,243 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	fieldEnt "taskchord/internal/pkg/field/ent"
+)
+
+func (h *CommandHandler) handleFieldCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	switch subcommand.Name {
+	case "define":
+		h.handleFieldDefine(s, i, subcommand.Options)
+	case "set":
+		h.handleFieldSet(s, i, subcommand.Options)
+	case "list":
+		h.handleFieldList(s, i)
+	}
+}
+
+func (h *CommandHandler) handleFieldDefine(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	// Only members who can manage the guild may define fields
+	if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to define fields.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	var name, kind, choices string
+	for _, opt := range options {
+		switch opt.Name {
+		case "name":
+			name = opt.StringValue()
+		case "kind":
+			kind = opt.StringValue()
+		case "options":
+			choices = opt.StringValue()
+		}
+	}
+
+	definition, err := h.fieldController.DefineField(i.GuildID, name, kind, choices)
+	if err != nil {
+		log.Printf("Error defining field: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to define the field. Names must be unique, and single-select fields need 2 to 25 comma separated options.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Field **%s** (%s) was added to the tasks of this server.", definition.Name, definition.Kind),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleFieldSet(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+
+	var id, name, value string
+	for _, opt := range options {
+		switch opt.Name {
+		case "id":
+			id = opt.StringValue()
+		case "field":
+			name = opt.StringValue()
+		case "value":
+			value = opt.StringValue()
+		}
+	}
+
+	// Fields can be set by the author and the executor of a task, or by managers
+	task, err := h.taskController.GetTask(guildID, id)
+	if err == nil && task.UserID != userID && task.ExecutorID != userID && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		err = fmt.Errorf("task with ID %s is not yours", id)
+	}
+	if err != nil {
+		log.Printf("Error setting field: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to set the field. Make sure the task exists and you are its author or executor.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Dates may be typed like due dates
+	definition, err := h.fieldController.GetDefinition(guildID, name)
+	if err == nil && definition.Kind == fieldEnt.Date && value != "" {
+		if day, parseErr := parseDueDate(value, memberToday(h.userController, guildID, userID)); parseErr == nil {
+			value = formatDueDate(day)
+		}
+	}
+
+	var taskValue fieldEnt.TaskValue
+	if err == nil {
+		taskValue, err = h.fieldController.SetValue(guildID, task.ID, name, value)
+	}
+	if err != nil {
+		log.Printf("Error setting field: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to set the field. Make sure it exists and the value fits its kind: a number, a date such as 2024-12-31, a user, or one of its options.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	content := fmt.Sprintf("Field **%s** of task **%s %s** was cleared.", taskValue.Definition.Name, task.Reference(), task.Title)
+	if taskValue.Value != "" {
+		content = fmt.Sprintf("Field **%s** of task **%s %s** is now %s.", taskValue.Definition.Name, task.Reference(), task.Title, formatFieldValue(taskValue))
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			AllowedMentions: &discordgo.MessageAllowedMentions{}, // User fields should not ping
+		},
+	})
+}
+
+func (h *CommandHandler) handleFieldList(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	definitions, err := h.fieldController.GetDefinitions(i.GuildID)
+	if err != nil {
+		log.Printf("Error fetching fields: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the fields. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Fields:",
+		Color: 0x00FF00, // Green color
+	}
+	if len(definitions) == 0 {
+		embed.Description = "No fields yet. Define one with /field define."
+	}
+
+	var lines []string
+	for _, definition := range definitions {
+		line := fmt.Sprintf("**%s** — %s", definition.Name, definition.Kind)
+		if definition.Kind == fieldEnt.Select {
+			line += ": " + strings.Join(definition.Choices(), ", ")
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		embed.Description = strings.Join(lines, "\n")
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// formatFieldValue renders a stored field value for reading
+func formatFieldValue(value fieldEnt.TaskValue) string {
+	if value.Definition.Kind == fieldEnt.User {
+		return fmt.Sprintf("<@%s>", value.Value)
+	}
+	return value.Value
+}
+
+// handleFieldAutocomplete suggests the custom fields of the guild
+func (h *CommandHandler) handleFieldAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	definitions, err := h.fieldController.GetDefinitions(i.GuildID)
+	if err != nil {
+		log.Printf("Error fetching fields: %v", err)
+	}
+
+	var names []string
+	for _, definition := range definitions {
+		names = append(names, definition.Name)
+	}
+	respondChoices(s, i, names, query)
+}
+
+// handleFieldValueAutocomplete suggests the options of the single-select field being set
+func (h *CommandHandler) handleFieldValueAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	var choices []string
+	for _, opt := range i.ApplicationCommandData().Options[0].Options {
+		if opt.Name != "field" {
+			continue
+		}
+		definition, err := h.fieldController.GetDefinition(i.GuildID, opt.StringValue())
+		if err == nil {
+			choices = definition.Choices()
+		}
+	}
+	respondChoices(s, i, choices, query)
+}
+
+// respondChoices answers an autocomplete request with the values that contain the query
+func respondChoices(s *discordgo.Session, i *discordgo.InteractionCreate, values []string, query string) {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	query = strings.ToLower(query)
+	for _, value := range values {
+		if len(choices) == 25 {
+			break
+		}
+		if strings.Contains(strings.ToLower(value), query) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: value, Value: value})
+		}
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to autocomplete: %v", err)
+	}
+}
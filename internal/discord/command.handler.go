@@ -5,21 +5,101 @@ import (
 	"fmt"
 	"github.com/bwmarrin/discordgo"
 	"log"
-	"strconv"
+	"strings"
+	calendarCtrl "taskchord/internal/pkg/calendar/ctrl"
+	digestCtrl "taskchord/internal/pkg/digest/ctrl"
+	exportCtrl "taskchord/internal/pkg/export/ctrl"
+	fieldCtrl "taskchord/internal/pkg/field/ctrl"
+	guildCtrl "taskchord/internal/pkg/guild/ctrl"
+	importerCtrl "taskchord/internal/pkg/importer/ctrl"
+	importerEnt "taskchord/internal/pkg/importer/ent"
+	milestoneCtrl "taskchord/internal/pkg/milestone/ctrl"
+	notifyCtrl "taskchord/internal/pkg/notify/ctrl"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+	priorityCtrl "taskchord/internal/pkg/priority/ctrl"
+	projectCtrl "taskchord/internal/pkg/project/ctrl"
+	searchCtrl "taskchord/internal/pkg/search/ctrl"
+	statsCtrl "taskchord/internal/pkg/stats/ctrl"
 	"taskchord/internal/pkg/task/ctrl"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	templateCtrl "taskchord/internal/pkg/template/ctrl"
+	trackCtrl "taskchord/internal/pkg/track/ctrl"
+	userCtrl "taskchord/internal/pkg/user/ctrl"
+	viewCtrl "taskchord/internal/pkg/view/ctrl"
+	workflowCtrl "taskchord/internal/pkg/workflow/ctrl"
+	"time"
 )
 
 type CommandHandler struct {
-	taskController ctrl.TaskController
+	taskController      ctrl.TaskController
+	guildController     *guildCtrl.GuildController
+	notifyController    *notifyCtrl.NotifyController
+	digestController    *digestCtrl.DigestController
+	userController      *userCtrl.UserController
+	trackController     *trackCtrl.TrackController
+	statsController     *statsCtrl.StatsController
+	milestoneController *milestoneCtrl.MilestoneController
+	projectController   *projectCtrl.ProjectController
+	priorityController  *priorityCtrl.PriorityController
+	workflowController  *workflowCtrl.WorkflowController
+	fieldController     *fieldCtrl.FieldController
+	templateController  *templateCtrl.TemplateController
+	searchController    *searchCtrl.SearchController
+	viewController      *viewCtrl.ViewController
+	exportController    *exportCtrl.ExportController
+	importController    *importerCtrl.ImportController
+	calendarController  *calendarCtrl.CalendarController
+	notifier            *Notifier
+	linkLimiter         *linkLimiter
+	drafts              *pendingStore[taskDraft]
+	bulkOperations      *pendingStore[bulkOperation]
+	imports             *pendingStore[importerEnt.Plan]
+	calendarURL         string // Public base URL of the calendar server, empty while feeds are disabled
 }
 
 // NewCommandHandler creates a new instance of CommandHandler
-func NewCommandHandler(taskController ctrl.TaskController) *CommandHandler {
-	return &CommandHandler{taskController: taskController}
+func NewCommandHandler(taskController ctrl.TaskController, guildController *guildCtrl.GuildController, notifyController *notifyCtrl.NotifyController, digestController *digestCtrl.DigestController, userController *userCtrl.UserController, trackController *trackCtrl.TrackController, statsController *statsCtrl.StatsController, milestoneController *milestoneCtrl.MilestoneController, projectController *projectCtrl.ProjectController, priorityController *priorityCtrl.PriorityController, workflowController *workflowCtrl.WorkflowController, fieldController *fieldCtrl.FieldController, templateController *templateCtrl.TemplateController, searchController *searchCtrl.SearchController, viewController *viewCtrl.ViewController, exportController *exportCtrl.ExportController, importController *importerCtrl.ImportController, calendarController *calendarCtrl.CalendarController, notifier *Notifier) *CommandHandler {
+	return &CommandHandler{
+		taskController:      taskController,
+		guildController:     guildController,
+		notifyController:    notifyController,
+		digestController:    digestController,
+		userController:      userController,
+		trackController:     trackController,
+		statsController:     statsController,
+		milestoneController: milestoneController,
+		projectController:   projectController,
+		priorityController:  priorityController,
+		workflowController:  workflowController,
+		fieldController:     fieldController,
+		templateController:  templateController,
+		searchController:    searchController,
+		viewController:      viewController,
+		exportController:    exportController,
+		importController:    importController,
+		calendarController:  calendarController,
+		notifier:            notifier,
+		linkLimiter:         newLinkLimiter(),
+		drafts:              newPendingStore[taskDraft](),
+		bulkOperations:      newPendingStore[bulkOperation](),
+		imports:             newPendingStore[importerEnt.Plan](),
+	}
 }
 
 // HandleCommand processes the commands issued by users
 func (h *CommandHandler) HandleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		h.handleAutocomplete(s, i)
+		return
+	case discordgo.InteractionMessageComponent:
+		h.handleComponent(s, i)
+		return
+	case discordgo.InteractionApplicationCommand:
+	default:
+		return
+	}
+
 	switch i.ApplicationCommandData().Name {
 	case "create":
 		h.handleCreateCommand(s, i)
@@ -29,6 +109,77 @@ func (h *CommandHandler) HandleCommand(s *discordgo.Session, i *discordgo.Intera
 		h.handleDeleteCommand(s, i)
 	case "update":
 		h.handleUpdateCommand(s, i)
+	case "claim":
+		h.handleClaimCommand(s, i)
+	case "assignment":
+		h.handleAssignmentCommand(s, i)
+	case "notify":
+		h.handleNotifyCommand(s, i)
+	case "digest":
+		h.handleDigestCommand(s, i)
+	case "timezone":
+		h.handleTimezoneCommand(s, i)
+	case "track":
+		h.handleTrackCommand(s, i)
+	case "report":
+		h.handleReportCommand(s, i)
+	case "stats":
+		h.handleStatsCommand(s, i)
+	case "chart":
+		h.handleChartCommand(s, i)
+	case "milestone":
+		h.handleMilestoneCommand(s, i)
+	case "project":
+		h.handleProjectCommand(s, i)
+	case "board":
+		h.handleBoardCommand(s, i)
+	case "autolink":
+		h.handleAutolinkCommand(s, i)
+	case "priority":
+		h.handlePriorityCommand(s, i)
+	case "workflow":
+		h.handleWorkflowCommand(s, i)
+	case "field":
+		h.handleFieldCommand(s, i)
+	case "template":
+		h.handleTemplateCommand(s, i)
+	case "checklist":
+		h.handleChecklistCommand(s, i)
+	case "clone":
+		h.handleCloneCommand(s, i)
+	case "merge":
+		h.handleMergeCommand(s, i)
+	case "move":
+		h.handleMoveCommand(s, i)
+	case "comment":
+		h.handleCommentCommand(s, i)
+	case "watch":
+		h.handleWatchCommand(s, i)
+	case "search":
+		h.handleSearchCommand(s, i)
+	case "view":
+		h.handleViewCommand(s, i)
+	case "bulk":
+		h.handleBulkCommand(s, i)
+	case "export":
+		h.handleExportCommand(s, i)
+	case "import":
+		h.handleImportCommand(s, i)
+	case "calendar":
+		h.handleCalendarCommand(s, i)
+	}
+}
+
+// handleComponent routes clicks on message components by the prefix of their custom ID
+func (h *CommandHandler) handleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	prefix, _, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+	switch prefix {
+	case "duplicate":
+		h.handleDuplicateComponent(s, i)
+	case "bulk":
+		h.handleBulkComponent(s, i)
+	case "import":
+		h.handleImportComponent(s, i)
 	}
 }
 
@@ -36,59 +187,226 @@ func (h *CommandHandler) HandleCommand(s *discordgo.Session, i *discordgo.Intera
 func (h *CommandHandler) handleCreateCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	options := i.ApplicationCommandData().Options
 
-	// Extract required options
-	title := options[0].StringValue()
-	description := options[1].StringValue()
-
-	// Set default values for optional options
-	priority := "Medium"
+	// Set default values for optional options; the default priority comes from the guild's scale
+	var title, description string
+	priority := ""
 	executorID := i.Interaction.Member.User.ID // Default to the creator
+	executorGiven := false
+	roleID := ""
+	var dueAt *time.Time
+	var estimate int
+	var milestoneID *uint
+	var projectName, templateName string
+	var tags, checklist []string
 
 	// Process optional options dynamically
-	for _, opt := range options[2:] {
+	for _, opt := range options {
 		switch opt.Type {
 		case discordgo.ApplicationCommandOptionString:
-			priority = opt.StringValue() // Handle priority
+			switch opt.Name {
+			case "title":
+				title = opt.StringValue()
+			case "description":
+				description = opt.StringValue()
+			case "priority":
+				priority = opt.StringValue() // Handle priority
+			case "tags":
+				tags = splitList(opt.StringValue(), ",")
+			case "checklist":
+				checklist = splitList(opt.StringValue(), ";")
+			case "template":
+				templateName = opt.StringValue()
+			case "due":
+				var err error
+				dueAt, err = parseDueDate(opt.StringValue(), memberToday(h.userController, i.GuildID, i.Member.User.ID))
+				if err != nil {
+					s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+						Type: discordgo.InteractionResponseChannelMessageWithSource,
+						Data: &discordgo.InteractionResponseData{
+							Content: "Failed to create task. The due date must look like 2024-12-31, today, tomorrow, +3d or +2w.",
+							Flags:   discordgo.MessageFlagsEphemeral,
+						},
+					})
+					return
+				}
+			case "estimate":
+				var err error
+				estimate, err = parseEstimate(opt.StringValue())
+				if err != nil {
+					s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+						Type: discordgo.InteractionResponseChannelMessageWithSource,
+						Data: &discordgo.InteractionResponseData{
+							Content: "Failed to create task. The estimate must look like 4h or 1h30m.",
+							Flags:   discordgo.MessageFlagsEphemeral,
+						},
+					})
+					return
+				}
+
+			case "milestone":
+				var err error
+				milestoneID, err = h.resolveMilestone(i.GuildID, opt.StringValue())
+				if err != nil || *milestoneID == 0 {
+					s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+						Type: discordgo.InteractionResponseChannelMessageWithSource,
+						Data: &discordgo.InteractionResponseData{
+							Content: "Failed to create task. The milestone does not exist or is already closed.",
+							Flags:   discordgo.MessageFlagsEphemeral,
+						},
+					})
+					return
+				}
+			case "project":
+				projectName = opt.StringValue()
+			}
 		case discordgo.ApplicationCommandOptionUser:
 			executorID = opt.UserValue(nil).ID // Handle executor
+		case discordgo.ApplicationCommandOptionMentionable:
+			// The executor may be either a user or a role
+			executorGiven = true
+			mentionedID := opt.Value.(string)
+			if _, isRole := i.ApplicationCommandData().Resolved.Roles[mentionedID]; isRole {
+				roleID = mentionedID
+			} else {
+				executorID = mentionedID
+			}
+		}
+	}
+
+	// A template fills in whatever the options leave out
+	if templateName != "" {
+		template, err := h.templateController.GetTemplate(i.GuildID, templateName)
+		if err != nil {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Failed to create task. The template does not exist.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		template = template.Expand(h.templateVariables(s, i))
+		if title == "" {
+			title = template.Title
 		}
+		if description == "" {
+			description = template.Description
+		}
+		if priority == "" {
+			priority = template.Priority
+		}
+		if tags == nil {
+			tags = template.TagNames()
+		}
+		if checklist == nil {
+			checklist = template.ChecklistItems()
+		}
+		if !executorGiven {
+			if template.RoleID != "" {
+				roleID = template.RoleID
+			} else if template.ExecutorID != "" {
+				executorID = template.ExecutorID
+			}
+		}
+	}
+
+	if title == "" || description == "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to create task. A title and a description are required unless a template provides them.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Without a project option the task joins the project of the channel, if any
+	project, err := h.resolveProject(s, i.GuildID, i.ChannelID, projectName)
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to create task. The project does not exist.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	var projectID *uint
+	if project != nil {
+		projectID = &project.ID
+	}
+
+	draft := taskDraft{
+		title:       title,
+		description: description,
+		priority:    priority,
+		executorID:  executorID,
+		roleID:      roleID,
+		dueAt:       dueAt,
+		estimate:    estimate,
+		milestoneID: milestoneID,
+		projectID:   projectID,
+		tags:        tags,
+		checklist:   checklist,
+	}
+
+	// Likely duplicates are shown first, with buttons to create the task anyway or watch one of them
+	if h.offerDuplicates(s, i, draft) {
+		return
+	}
+	h.createTask(s, i, draft)
+}
+
+// createTask creates the task of a /create draft and announces it in the channel.
+// Failures are answered here and returned as well.
+func (h *CommandHandler) createTask(s *discordgo.Session, i *discordgo.InteractionCreate, draft taskDraft) (taskEnt.Task, error) {
+	if draft.roleID != "" {
+		return h.createRoleTask(s, i, draft.title, draft.description, draft.priority, draft.roleID, draft.dueAt, draft.estimate, draft.milestoneID, draft.projectID, draft.tags, draft.checklist)
 	}
 
 	// Create task
 	userID := i.Member.User.ID
 	guildID := i.GuildID
+	title := draft.title
+	executorID := draft.executorID
 
-	taskIdInGuild, err := h.taskController.CreateTask(guildID, userID, title, description, priority, executorID)
+	task, err := h.taskController.CreateTask(guildID, userID, title, draft.description, draft.priority, executorID, draft.dueAt, draft.estimate, draft.milestoneID, draft.projectID, draft.tags, draft.checklist)
 	if err != nil {
 		log.Printf("Error creating task: %v", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "Failed to create task. Please try again later.",
+				Content: "Failed to create task. Make sure the priority is on the scale of the server (see /priority list), there are at most 10 tags and 25 checklist items, or try again later.",
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
-		return
+		return taskEnt.Task{}, err
 	}
 
 	// Create the non-ephemeral message with @mention
-	taskIDStr := strconv.FormatUint(uint64(taskIdInGuild), 10)
 	embed := &discordgo.MessageEmbed{
 		Color:       0x00FF00,
-		Description: fmt.Sprintf("Task **#%s %s** successfully created!", taskIDStr, title),
+		Description: fmt.Sprintf("Task **%s %s** successfully created!", task.Reference(), title),
 	}
 
-	// Mention the executor and author
-	mentionMessage := fmt.Sprintf("<@%s>, task **#%s %s** was assigned to you by <@%s>", executorID, taskIDStr, title, userID)
-
-	// Respond with the task creation details and mention message
+	// Respond with the task creation details
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Embeds:  []*discordgo.MessageEmbed{embed},
-			Content: mentionMessage, // Non-ephemeral message
+			Embeds: []*discordgo.MessageEmbed{embed}, // Non-ephemeral message
 		},
 	})
+
+	// Let the executor know, unless they assigned the task to themselves
+	if executorID != userID {
+		message := fmt.Sprintf("task **%s %s** was assigned to you by <@%s>", task.Reference(), title, userID)
+		h.notifier.Notify(s, guildID, i.ChannelID, executorID, notifyEnt.Assigned, message)
+	}
+	return task, nil
 }
 
 func (h *CommandHandler) handleUpdateCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -107,7 +425,11 @@ func (h *CommandHandler) handleUpdateCommand(s *discordgo.Session, i *discordgo.
 
 	userID := i.Interaction.Member.User.ID
 	guildID := i.GuildID
-	var id, title, description, priority, executorID string
+	var id, title, description, priority, executorID, status string
+	var dueAt *time.Time
+	var estimate int
+	var milestoneID *uint
+	var tags []string
 
 	options := i.ApplicationCommandData().Options
 	if len(options) == 0 {
@@ -124,11 +446,11 @@ func (h *CommandHandler) handleUpdateCommand(s *discordgo.Session, i *discordgo.
 	// Extract the task ID (required)
 	id = options[0].StringValue()
 
-	// Set default values for optional fields
-	priority = "Medium"
+	// Set default values for optional fields; empty values keep the current ones
+	priority = ""
 	title = ""
 	description = ""
-	executorID = userID
+	executorID = ""
 
 	// Process optional fields dynamically
 	for _, opt := range options[1:] {
@@ -141,6 +463,54 @@ func (h *CommandHandler) handleUpdateCommand(s *discordgo.Session, i *discordgo.
 				description = opt.StringValue()
 			case "priority":
 				priority = opt.StringValue()
+			case "status":
+				status = opt.StringValue()
+			case "tags":
+				// None removes every tag
+				tags = []string{}
+				if !strings.EqualFold(opt.StringValue(), "none") {
+					tags = splitList(opt.StringValue(), ",")
+				}
+			case "due":
+				var err error
+				dueAt, err = parseDueDate(opt.StringValue(), memberToday(h.userController, i.GuildID, i.Member.User.ID))
+				if err != nil {
+					s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+						Type: discordgo.InteractionResponseChannelMessageWithSource,
+						Data: &discordgo.InteractionResponseData{
+							Content: "Failed to update task. The due date must look like 2024-12-31, today, tomorrow, +3d or +2w.",
+							Flags:   discordgo.MessageFlagsEphemeral,
+						},
+					})
+					return
+				}
+			case "estimate":
+				var err error
+				estimate, err = parseEstimate(opt.StringValue())
+				if err != nil {
+					s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+						Type: discordgo.InteractionResponseChannelMessageWithSource,
+						Data: &discordgo.InteractionResponseData{
+							Content: "Failed to update task. The estimate must look like 4h or 1h30m.",
+							Flags:   discordgo.MessageFlagsEphemeral,
+						},
+					})
+					return
+				}
+
+			case "milestone":
+				var err error
+				milestoneID, err = h.resolveMilestone(i.GuildID, opt.StringValue())
+				if err != nil {
+					s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+						Type: discordgo.InteractionResponseChannelMessageWithSource,
+						Data: &discordgo.InteractionResponseData{
+							Content: "Failed to update task. The milestone does not exist or is already closed.",
+							Flags:   discordgo.MessageFlagsEphemeral,
+						},
+					})
+					return
+				}
 			}
 		case discordgo.ApplicationCommandOptionUser:
 			if opt.Name == "executor" {
@@ -150,11 +520,11 @@ func (h *CommandHandler) handleUpdateCommand(s *discordgo.Session, i *discordgo.
 	}
 
 	// Validate and assign the title, description, priority, and executor
-	if title == "" && description == "" && priority == "" && executorID == userID {
+	if title == "" && description == "" && priority == "" && executorID == "" && status == "" && dueAt == nil && estimate == 0 && milestoneID == nil && tags == nil {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "Failed to update task. Please provide at least one field (title, description, priority, or executor).",
+				Content: "Failed to update task. Please provide at least one field (title, description, priority, executor, status, due date, estimate, milestone, or tags).",
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -162,31 +532,29 @@ func (h *CommandHandler) handleUpdateCommand(s *discordgo.Session, i *discordgo.
 	}
 
 	// Call the controller to update the task
-	taskIdInGuild, err := h.taskController.UpdateTask(guildID, userID, title, description, priority, executorID, id)
+	task, err := h.taskController.UpdateTask(guildID, userID, title, description, priority, executorID, status, id, dueAt, estimate, milestoneID, tags)
 	if err != nil {
 		log.Printf("Error updating task: %v", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "Failed to update task. Please try again later.",
+				Content: "Failed to update task. Make sure the priority is on the scale of the server and the workflow allows the new status (see /priority list and /workflow show), or try again later.",
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	// If the executor was updated, mention the new executor
-	if executorID != userID {
-		taskIDStr := strconv.FormatUint(uint64(taskIdInGuild), 10)
-		mentionMessage := fmt.Sprintf("<@%s>, task **#%s %s** was reassigned to you by <@%s>", executorID, taskIDStr, title, userID)
-		s.ChannelMessageSend(i.ChannelID, mentionMessage)
+	// If the executor was updated, notify the new executor
+	if executorID != "" && executorID != userID {
+		message := fmt.Sprintf("task **%s %s** was reassigned to you by <@%s>", task.Reference(), task.Title, userID)
+		h.notifier.Notify(s, guildID, i.ChannelID, executorID, notifyEnt.Reassigned, message)
 	}
 
 	// Respond with the updated task info
-	taskIDStr := strconv.FormatUint(uint64(taskIdInGuild), 10)
 	embed := &discordgo.MessageEmbed{
 		Color:       0x00FF00, // Green color
-		Description: fmt.Sprintf("Task **#%s %s** successfully updated!", taskIDStr, title),
+		Description: fmt.Sprintf("Task **%s %s** successfully updated!", task.Reference(), task.Title),
 	}
 
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -235,13 +603,48 @@ func (h *CommandHandler) handleShowCommand(s *discordgo.Session, i *discordgo.In
 	guildID := i.GuildID
 	var id string
 
-	options := i.ApplicationCommandData().Options
-	if len(options) > 0 { // Check if the id option is provided
-		id = options[0].StringValue() // Guaranteed to be "High", "Medium", or "Low" from the select menu
+	var projectID *uint
+	var status, query string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "id":
+			id = opt.StringValue()
+		case "status":
+			status = opt.StringValue()
+		case "query":
+			query = opt.StringValue()
+		case "project":
+			project, err := h.projectController.GetProject(guildID, opt.StringValue())
+			if err != nil {
+				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseChannelMessageWithSource,
+					Data: &discordgo.InteractionResponseData{
+						Content: "Failed to fetch tasks. The project does not exist.",
+						Flags:   discordgo.MessageFlagsEphemeral,
+					},
+				})
+				return
+			}
+			projectID = &project.ID
+		}
+	}
+
+	// Relative dates of the query count from the member's current date
+	today := memberToday(h.userController, guildID, userID)
+	filter, err := h.taskController.CompileQuery(guildID, userID, query, today)
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Failed to fetch tasks. The query is not valid: %v.", err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
 	}
 
 	// Retrieve tasks from the database
-	tasks, err := h.taskController.GetTasksByUserID(guildID, userID, id)
+	tasks, err := h.taskController.GetTasksByUserID(guildID, userID, id, i.Member.Roles, projectID, status, filter)
 	if err != nil {
 		log.Printf("Error fetching tasks: %v", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -263,24 +666,119 @@ func (h *CommandHandler) handleShowCommand(s *discordgo.Session, i *discordgo.In
 
 	if len(tasks) == 0 {
 		embed.Description = "You have no tasks!"
+
+		// Merged tasks point to the task that took their place
+		if id != "" {
+			if target, err := h.taskController.GetMergeTarget(guildID, id); err == nil {
+				embed.Description = fmt.Sprintf("Task %s was merged into **%s %s**.", id, target.Reference(), target.Title)
+			}
+		}
 	} else {
-		for i, task := range tasks {
-			taskIDStr := strconv.FormatUint(uint64(task.TaskIdInGuild), 10)
+		location := h.userController.GetLocation(guildID, userID)
+
+		spent, err := h.trackController.GetSpentSeconds(tasks)
+		if err != nil {
+			log.Printf("Error fetching tracked time: %v", err)
+		}
+
+		milestones, err := h.milestoneController.GetMilestones(guildID, true)
+		if err != nil {
+			log.Printf("Error fetching milestones: %v", err)
+		}
+		milestoneNames := make(map[uint]string)
+		for _, milestone := range milestones {
+			milestoneNames[milestone.ID] = milestone.Name
+		}
+		projectNames := h.projectNames(guildID)
+
+		scale, err := h.priorityController.GetScale(guildID)
+		if err != nil {
+			log.Printf("Error fetching priority scale: %v", err)
+		}
+
+		// A single task takes the color of its priority
+		if level, ok := scale.Find(string(tasks[0].Priority)); ok && len(tasks) == 1 && level.Color != 0 {
+			embed.Color = level.Color
+		}
 
+		for i, task := range tasks {
 			// Use cached nickname retrieval
 			authorNickname := GetNicknameFromIDWithCache(task.UserID, s, guildID)
-			executorNickname := GetNicknameFromIDWithCache(task.ExecutorID, s, guildID)
+
+			// Unclaimed tasks are still waiting in their role queue
+			executor := fmt.Sprintf("<@&%s> (unclaimed)", task.RoleID)
+			if task.ExecutorID != "" {
+				executor = fmt.Sprintf("<@%s> (%s)", task.ExecutorID, GetNicknameFromIDWithCache(task.ExecutorID, s, guildID))
+			}
+
+			status := string(task.Status)
+			if task.CompletedAt != nil {
+				status += " (" + formatDateTime(*task.CompletedAt, location) + ")"
+			}
+
+			due := formatDueDate(task.DueAt)
+			if task.MilestoneID != nil {
+				due += "\nMilestone: " + milestoneNames[*task.MilestoneID]
+			}
+			if task.ProjectID != nil {
+				due += "\nProject: " + projectNames[*task.ProjectID]
+			}
+			if len(task.Tags) > 0 {
+				due += "\nTags: " + strings.Join(task.TagNames(), ", ")
+			}
+			if len(task.Checklist) > 0 && id == "" {
+				due += "\nChecklist: " + checklistProgress(task.Checklist)
+			}
+
+			// Custom fields are only listed when looking at a single task
+			if id != "" {
+				values, err := h.fieldController.GetValues(guildID, task.ID)
+				if err != nil {
+					log.Printf("Error fetching fields: %v", err)
+				}
+				for _, value := range values {
+					due += "\n" + value.Definition.Name + ": " + formatFieldValue(value)
+				}
+			}
 
 			description := fmt.Sprintf(
-				"Author: <@%s> (%s)\nExecutor: <@%s> (%s)\nPriority: %s\n**Description:**\n%s",
+				"Author: <@%s> (%s)\nExecutor: %s\nPriority: %s\nStatus: %s\nDue: %s\n**Description:**\n%s",
 				task.UserID, authorNickname,
-				task.ExecutorID, executorNickname,
-				string(task.Priority), task.Description,
+				executor,
+				priorityLabel(scale, string(task.Priority)), status, due, task.Description,
 			)
 
+			// The items of the checklist are only listed when looking at a single task
+			if len(task.Checklist) > 0 && id != "" {
+				description += "\n**Checklist (" + checklistProgress(task.Checklist) + "):**\n" + checklistLines(task.Checklist)
+			}
+
+			// So are the watchers and the number of comments
+			if id != "" {
+				if watchers, err := h.taskController.GetWatchers(task.ID); err == nil && len(watchers) > 0 {
+					mentions := make([]string, 0, len(watchers))
+					for _, watcher := range watchers {
+						mentions = append(mentions, fmt.Sprintf("<@%s>", watcher))
+					}
+					description += "\nWatchers: " + strings.Join(mentions, ", ")
+				}
+				if comments, err := h.taskController.GetComments(task.ID); err == nil && len(comments) > 0 {
+					description += fmt.Sprintf("\nComments: %d (see /comment list)", len(comments))
+				}
+			}
+
+			// Show tracked time, compared with the estimate when looking at a single task
+			if tracked := time.Duration(spent[task.ID]) * time.Second; tracked > 0 || task.Estimate > 0 {
+				timeLine := "Time: " + formatDuration(tracked)
+				if id != "" && task.Estimate > 0 {
+					timeLine += " of " + formatDuration(time.Duration(task.Estimate)*time.Minute) + " estimated"
+				}
+				description = timeLine + "\n" + description
+			}
+
 			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-				Name:   "**#" + taskIDStr + " " + task.Title + "**",
-				Value:  description,
+				Name:   "**" + task.Reference() + " " + task.Title + "**",
+				Value:  truncateField(description),
 				Inline: false,
 			})
 
@@ -316,7 +814,7 @@ func (h *CommandHandler) handleDeleteCommand(s *discordgo.Session, i *discordgo.
 	}
 
 	// Call the controller to delete the task
-	taskIdInGuild, err := h.taskController.DeleteTask(guildID, userID, id)
+	reference, err := h.taskController.DeleteTask(guildID, userID, id)
 	if err != nil {
 		log.Printf("Error deleting task: %v", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -333,7 +831,7 @@ func (h *CommandHandler) handleDeleteCommand(s *discordgo.Session, i *discordgo.
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("Task #%s successfully deleted!", taskIdInGuild),
+			Content: fmt.Sprintf("Task %s successfully deleted!", reference),
 			Flags:   discordgo.MessageFlagsEphemeral,
 		},
 	})
@@ -0,0 +1,337 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	importerEnt "taskchord/internal/pkg/importer/ent"
+	"time"
+)
+
+// importSizeLimit is the largest file /import reads
+const importSizeLimit = 10 << 20
+
+// importClient downloads attachments, giving up on slow downloads
+var importClient = &http.Client{Timeout: 30 * time.Second}
+
+func (h *CommandHandler) handleImportCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Imports write tasks in the name of other members, so only managers run them
+	if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to import tasks.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	switch subcommand.Name {
+	case "file":
+		h.handleImportFile(s, i, subcommand.Options)
+	case "map":
+		h.handleImportMap(s, i, subcommand.Options)
+	case "mappings":
+		h.handleImportMappings(s, i)
+	}
+}
+
+func (h *CommandHandler) handleImportFile(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var source string
+	var attachment *discordgo.MessageAttachment
+	for _, opt := range options {
+		switch opt.Name {
+		case "source":
+			source = opt.StringValue()
+		case "file":
+			attachment = i.ApplicationCommandData().Resolved.Attachments[opt.Value.(string)]
+		}
+	}
+
+	if attachment == nil || attachment.Size > importSizeLimit {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to import. Attach an export file of at most 10 MB.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Downloading and reading the file can take longer than Discord waits for a reply
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		log.Printf("Error deferring import: %v", err)
+		return
+	}
+
+	data, err := downloadAttachment(attachment.URL)
+	var roles []string
+	if err == nil {
+		roles, err = guildRoleIDs(s, i.GuildID)
+	}
+	var plan importerEnt.Plan
+	if err == nil {
+		plan, err = h.importController.Plan(i.GuildID, i.Member.User.ID, source, data, roles)
+	}
+	if err != nil {
+		log.Printf("Error planning import: %v", err)
+		content := fmt.Sprintf("Failed to import: %v.", err)
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+		return
+	}
+
+	h.imports.put(i.ID, i.Member.User.ID, plan, time.Now())
+
+	content := fmt.Sprintf("This is a dry run; nothing was imported yet. Confirm to import %d tasks.", len(plan.Tasks))
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+		Embeds:  &[]*discordgo.MessageEmbed{importPlanEmbed(plan)},
+		Components: &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    fmt.Sprintf("Import %d tasks", len(plan.Tasks)),
+					Style:    discordgo.SuccessButton,
+					CustomID: "import:confirm:" + i.ID,
+				},
+				discordgo.Button{
+					Label:    "Cancel",
+					Style:    discordgo.SecondaryButton,
+					CustomID: "import:cancel:" + i.ID,
+				},
+			}},
+		},
+	})
+	if err != nil {
+		log.Printf("Error sending import preview: %v", err)
+	}
+}
+
+// handleImportComponent handles the buttons under an import preview. Their custom IDs are
+// import:confirm:<import> and import:cancel:<import>.
+func (h *CommandHandler) handleImportComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) < 3 {
+		return
+	}
+
+	plan, found := h.imports.take(parts[2], i.Member.User.ID, time.Now())
+	if !found {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "This preview expired or was already used. Run /import again.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if parts[1] != "confirm" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    "The import was cancelled; nothing was imported.",
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		return
+	}
+
+	// Storing many tasks can take longer than Discord waits for a reply
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		log.Printf("Error deferring import: %v", err)
+		return
+	}
+
+	content := ""
+	tasks, err := h.importController.Commit(i.GuildID, plan)
+	if err != nil {
+		log.Printf("Error importing tasks: %v", err)
+		content = "The import failed and nothing was imported. Please try again later."
+	} else {
+		content = fmt.Sprintf("Imported %d tasks, from %s to %s.", len(tasks), tasks[0].Reference(), tasks[len(tasks)-1].Reference())
+	}
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &content,
+		Components: &[]discordgo.MessageComponent{},
+	})
+	if err != nil {
+		log.Printf("Error reporting import: %v", err)
+	}
+}
+
+func (h *CommandHandler) handleImportMap(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var name, userID string
+	for _, opt := range options {
+		switch opt.Name {
+		case "name":
+			name = opt.StringValue()
+		case "user":
+			userID = opt.UserValue(nil).ID
+		}
+	}
+
+	if err := h.importController.SetMapping(i.GuildID, name, userID); err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Failed to map the user: %v.", err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	content := fmt.Sprintf("Imports now give the tasks of **%s** to <@%s>.", name, userID)
+	if userID == "" {
+		content = fmt.Sprintf("The mapping of **%s** was removed.", name)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleImportMappings(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	mappings, err := h.importController.GetMappings(i.GuildID)
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the user mappings. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	var lines []string
+	for _, mapping := range mappings {
+		lines = append(lines, fmt.Sprintf("%s → <@%s>", mapping.Name, mapping.UserID))
+	}
+	description := strings.Join(lines, "\n")
+	if len(lines) == 0 {
+		description = "No user mappings yet. Add one with /import map."
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "User Mappings:",
+				Description: truncateField(description),
+				Color:       0x00FF00, // Green color
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// downloadAttachment reads an uploaded file, refusing files above the import size limit
+func downloadAttachment(url string) ([]byte, error) {
+	response, err := importClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the file could not be downloaded (%s)", response.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, importSizeLimit+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > importSizeLimit {
+		return nil, fmt.Errorf("the file is larger than 10 MB")
+	}
+	return data, nil
+}
+
+// importPlanEmbed sums up what an import would bring in
+func importPlanEmbed(plan importerEnt.Plan) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       "Import Preview:",
+		Description: fmt.Sprintf("%d tasks, %d of them done.", len(plan.Tasks), plan.Done),
+		Color:       0x00FF00, // Green color
+		Fields:      []*discordgo.MessageEmbedField{},
+	}
+	if plan.Skipped > 0 {
+		embed.Description += fmt.Sprintf(" %d items without a title are skipped.", plan.Skipped)
+	}
+
+	comments, watchers, values := 0, 0, 0
+	for index := range plan.Tasks {
+		comments += len(plan.Comments[index])
+		watchers += len(plan.Watchers[index])
+		values += len(plan.Values[index])
+	}
+	if comments > 0 {
+		embed.Description += fmt.Sprintf(" %d comments come along.", comments)
+	}
+	if watchers > 0 {
+		embed.Description += fmt.Sprintf(" %d watchers come along.", watchers)
+	}
+	if values > 0 {
+		embed.Description += fmt.Sprintf(" %d custom field values come along.", values)
+	}
+
+	var lines []string
+	for index, task := range plan.Tasks {
+		if index == 10 {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(plan.Tasks)-10))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("**%s** (%s, %s, <@%s>)", task.Title, task.Status, task.Priority, task.ExecutorID))
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Tasks",
+		Value: truncateField(strings.Join(lines, "\n")),
+	})
+
+	if len(plan.Unmapped) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Users without a mapping (you stand in for them; add mappings with /import map)",
+			Value: truncateField(strings.Join(plan.Unmapped, ", ")),
+		})
+	}
+	if len(plan.Missing) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Not in this server (left out)",
+			Value: truncateField(strings.Join(plan.Missing, ", ")),
+		})
+	}
+	return embed
+}
+
+// guildRoleIDs lists the IDs of the roles of a guild, from the state when it has them
+func guildRoleIDs(s *discordgo.Session, guildID string) ([]string, error) {
+	var roles []*discordgo.Role
+	if guild, err := s.State.Guild(guildID); err == nil {
+		roles = guild.Roles
+	} else if roles, err = s.GuildRoles(guildID); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, role.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,195 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strconv"
+	"strings"
+	milestoneEnt "taskchord/internal/pkg/milestone/ent"
+	"time"
+)
+
+func (h *CommandHandler) handleMilestoneCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	// Only members who can manage the guild may plan milestones
+	if subcommand.Name != "show" && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to create or close milestones.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	today := memberToday(h.userController, guildID, userID)
+
+	var name string
+	var err error
+	switch subcommand.Name {
+	case "create":
+		var milestone milestoneEnt.Milestone
+		var startDate, endDate *time.Time
+		name = subcommand.Options[0].StringValue()
+		startDate, err = parseDueDate(subcommand.Options[1].StringValue(), today)
+		if err == nil {
+			endDate, err = parseDueDate(subcommand.Options[2].StringValue(), today)
+		}
+		if err == nil {
+			milestone, err = h.milestoneController.CreateMilestone(guildID, name, *startDate, *endDate)
+			name = milestone.Name
+		}
+	case "close":
+		var milestone milestoneEnt.Milestone
+		milestone, err = h.milestoneController.CloseMilestone(guildID, subcommand.Options[0].StringValue())
+		name = milestone.Name
+	case "show":
+		if len(subcommand.Options) > 0 {
+			name = subcommand.Options[0].StringValue()
+		}
+	}
+	if err != nil {
+		log.Printf("Error updating milestone: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Failed to %s the milestone. Dates must look like 2024-12-31 or +2w, and names must be unique.", subcommand.Name),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Every subcommand answers with the progress of the milestone
+	progress, err := h.milestoneController.GetProgress(guildID, name, today)
+	if err != nil {
+		log.Printf("Error fetching milestone progress: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the milestone. Make sure it exists, or create one with /milestone create.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{milestoneEmbed(progress, today)},
+		},
+	})
+}
+
+// milestoneEmbed renders the progress of a milestone and the tasks that put it at risk
+func milestoneEmbed(progress milestoneEnt.Progress, today time.Time) *discordgo.MessageEmbed {
+	milestone := progress.Milestone
+
+	state := "Open"
+	if milestone.ClosedAt != nil {
+		state = "Closed"
+	}
+	remaining := "Ended"
+	if progress.DaysRemaining > 0 {
+		remaining = strconv.Itoa(progress.DaysRemaining)
+	} else if today.Before(milestone.StartDate) {
+		remaining = "Not started"
+	}
+
+	percent := int64(0)
+	if progress.Total > 0 {
+		percent = progress.Done * 100 / progress.Total
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Milestone: " + milestone.Name,
+		Description: fmt.Sprintf("%s to %s · %s", formatDueDate(&milestone.StartDate), formatDueDate(&milestone.EndDate), state),
+		Color:       0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Progress",
+				Value:  fmt.Sprintf("%s %d/%d done (%d%%)", textBar(progress.Done, progress.Total), progress.Done, progress.Total, percent),
+				Inline: true,
+			},
+			{Name: "Days remaining", Value: remaining, Inline: true},
+		},
+	}
+
+	if len(progress.AtRisk) > 0 {
+		var lines []string
+		for _, task := range progress.AtRisk {
+			var reasons []string
+			if task.ExecutorID == "" {
+				reasons = append(reasons, "unclaimed")
+			}
+			if task.DueAt != nil && task.DueAt.Before(today) {
+				reasons = append(reasons, "overdue")
+			} else if task.DueAt != nil && task.DueAt.After(milestone.EndDate) {
+				reasons = append(reasons, "due "+formatDueDate(task.DueAt)+", after the end")
+			}
+			lines = append(lines, fmt.Sprintf("**%s %s** (%s)", task.Reference(), task.Title, strings.Join(reasons, ", ")))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "At risk",
+			Value: truncateField(strings.Join(lines, "\n")),
+		})
+	}
+
+	return embed
+}
+
+// resolveMilestone turns a milestone name typed in /create or /update into its primary key.
+// "none" resolves to zero, which takes a task out of its milestone.
+func (h *CommandHandler) resolveMilestone(guildID, name string) (*uint, error) {
+	var milestoneID uint
+	if !strings.EqualFold(strings.TrimSpace(name), "none") {
+		milestone, err := h.milestoneController.GetOpenMilestone(guildID, name)
+		if err != nil {
+			return nil, err
+		}
+		milestoneID = milestone.ID
+	}
+	return &milestoneID, nil
+}
+
+// handleMilestoneAutocomplete suggests milestones matching what the user typed so far.
+// Only /milestone show and /chart offer closed milestones, as tasks cannot be added to them.
+func (h *CommandHandler) handleMilestoneAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	command := i.ApplicationCommandData().Name
+	includeClosed := command == "chart" || (command == "milestone" && i.ApplicationCommandData().Options[0].Name == "show")
+
+	milestones, err := h.milestoneController.GetMilestones(i.GuildID, includeClosed)
+	if err != nil {
+		log.Printf("Error fetching milestones: %v", err)
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if command == "update" {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: "None (remove from milestone)", Value: "none"})
+	}
+	query = strings.ToLower(query)
+	for _, milestone := range milestones {
+		if len(choices) == 25 {
+			break
+		}
+		if strings.Contains(strings.ToLower(milestone.Name), query) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: milestone.Name, Value: milestone.Name})
+		}
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to milestone autocomplete: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+)
+
+func (h *CommandHandler) handleNotifyCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	var err error
+	switch subcommand.Name {
+	case "set":
+		err = h.notifyController.SetPreference(userID, subcommand.Options[0].StringValue(), subcommand.Options[1].StringValue())
+	case "quiet":
+		err = h.notifyController.SetQuietHours(userID, int(subcommand.Options[0].IntValue()), int(subcommand.Options[1].IntValue()))
+	}
+	if err != nil {
+		log.Printf("Error updating notification settings: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to update notification settings. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Every subcommand answers with the resulting settings
+	embed, err := h.notifySettingsEmbed(i.GuildID, userID)
+	if err != nil {
+		log.Printf("Error fetching notification settings: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch notification settings. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// notifySettingsEmbed renders the notification preferences and quiet hours of a user
+func (h *CommandHandler) notifySettingsEmbed(guildID, userID string) (*discordgo.MessageEmbed, error) {
+	preferences, err := h.notifyController.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	quiet, err := h.notifyController.GetQuietHours(userID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := h.notifyController.CountPending(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, event := range notifyEnt.Events {
+		lines = append(lines, fmt.Sprintf("%s: %s", event, preferences[event]))
+	}
+
+	quietHours := "Off"
+	if quiet != nil {
+		quietHours = fmt.Sprintf("%02d:00 – %02d:00 %s", quiet.StartHour, quiet.EndHour, h.userController.GetLocation(guildID, userID))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: "Your Notification Settings:",
+		Color: 0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Events", Value: strings.Join(lines, "\n")},
+			{Name: "Quiet hours", Value: quietHours},
+			{Name: "Waiting for digest", Value: fmt.Sprintf("%d notification(s)", pending)},
+		},
+	}, nil
+}
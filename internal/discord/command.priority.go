@@ -0,0 +1,149 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	priorityEnt "taskchord/internal/pkg/priority/ent"
+)
+
+func (h *CommandHandler) handlePriorityCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	// Only members who can manage the guild may change the scale
+	if subcommand.Name != "list" && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to change the priority scale.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	var err error
+	var failure string
+	switch subcommand.Name {
+	case "set":
+		_, err = h.priorityController.SetScale(guildID, subcommand.Options[0].StringValue())
+		failure = "Failed to set the priority scale. List 2 to 25 unique levels of at most 20 characters, separated by commas."
+	case "style":
+		var name, color, emoji string
+		for _, opt := range subcommand.Options {
+			switch opt.Name {
+			case "level":
+				name = opt.StringValue()
+			case "color":
+				color = opt.StringValue()
+			case "emoji":
+				emoji = opt.StringValue()
+			}
+		}
+		_, err = h.priorityController.StyleLevel(guildID, name, color, emoji)
+		failure = "Failed to style the priority. Make sure it is on the scale and give a color such as #FF0000 or an emoji."
+	case "reset":
+		err = h.priorityController.ResetScale(guildID)
+		failure = "Failed to reset the priority scale. Please try again later."
+	}
+	if err != nil {
+		log.Printf("Error updating priority scale: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: failure,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Every subcommand answers with the resulting scale
+	scale, err := h.priorityController.GetScale(guildID)
+	if err != nil {
+		log.Printf("Error fetching priority scale: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the priority scale. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{priorityScaleEmbed(scale)},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// priorityScaleEmbed lists the levels of a priority scale, the most urgent first
+func priorityScaleEmbed(scale priorityEnt.Scale) *discordgo.MessageEmbed {
+	var lines []string
+	for _, level := range scale {
+		line := fmt.Sprintf("%d. %s", level.Position+1, level.Label())
+		if level.Color != 0 {
+			line += fmt.Sprintf(" (#%06X)", level.Color)
+		}
+		if level.Name == scale.Default().Name {
+			line += " — default"
+		}
+		lines = append(lines, line)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "Priority Scale:",
+		Description: strings.Join(lines, "\n"),
+		Color:       0x00FF00, // Green color
+	}
+}
+
+// priorityLabel renders a task priority with the emoji of its level, if it is still on the scale
+func priorityLabel(scale priorityEnt.Scale, priority string) string {
+	if level, ok := scale.Find(priority); ok {
+		return level.Label()
+	}
+	return priority
+}
+
+// handlePriorityAutocomplete suggests the levels of the guild's priority scale, the most urgent first
+func (h *CommandHandler) handlePriorityAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	scale, err := h.priorityController.GetScale(i.GuildID)
+	if err != nil {
+		log.Printf("Error fetching priority scale: %v", err)
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	query = strings.ToLower(query)
+	for _, level := range scale {
+		if len(choices) == 25 {
+			break
+		}
+		if !strings.Contains(strings.ToLower(level.Name), query) {
+			continue
+		}
+
+		// Custom emoji are not rendered in choices, so only Unicode emoji are shown
+		name := level.Name
+		if level.Emoji != "" && !strings.HasPrefix(level.Emoji, "<") {
+			name = level.Label()
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: level.Name})
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to priority autocomplete: %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	projectEnt "taskchord/internal/pkg/project/ent"
+)
+
+func (h *CommandHandler) handleProjectCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	// Only members who can manage the guild may change projects
+	if subcommand.Name != "list" && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to change projects.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// The channel options default to the channel the command was used in
+	channelID := i.ChannelID
+	var name, key string
+	for _, opt := range subcommand.Options {
+		switch opt.Name {
+		case "project", "name":
+			name = opt.StringValue()
+		case "key":
+			key = opt.StringValue()
+		case "channel":
+			channelID = opt.ChannelValue(nil).ID
+		}
+	}
+
+	var content string
+	var err error
+	switch subcommand.Name {
+	case "create":
+		var project projectEnt.Project
+		project, err = h.projectController.CreateProject(guildID, name)
+		if err == nil && key != "" {
+			project, err = h.projectController.SetKey(guildID, project.Name, key)
+		}
+		content = fmt.Sprintf("Project **%s** created. Use `/project bind` to make channels default to it.", project.Name)
+	case "key":
+		var project projectEnt.Project
+		project, err = h.projectController.SetKey(guildID, name, key)
+		content = fmt.Sprintf("Tasks of project **%s** are now referred to as %s-1, %s-2 and so on.", project.Name, project.Key, project.Key)
+	case "bind":
+		var project projectEnt.Project
+		project, err = h.projectController.BindChannel(guildID, name, channelID)
+		content = fmt.Sprintf("Tasks created in <#%s> now default to project **%s**.", channelID, project.Name)
+	case "unbind":
+		err = h.projectController.UnbindChannel(guildID, channelID)
+		content = fmt.Sprintf("<#%s> no longer defaults to a project.", channelID)
+	case "list":
+		var embed *discordgo.MessageEmbed
+		embed, err = h.projectListEmbed(guildID)
+		if err == nil {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Embeds: []*discordgo.MessageEmbed{embed},
+					Flags:  discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+	}
+
+	if err != nil {
+		log.Printf("Error updating projects: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to update projects. Make sure the project exists, and that names and keys are unique. Keys are a letter followed by up to nine letters or digits.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// projectListEmbed lists the projects of a guild with the channels bound to them
+func (h *CommandHandler) projectListEmbed(guildID string) (*discordgo.MessageEmbed, error) {
+	projects, err := h.projectController.GetProjects(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "Projects:",
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+	if len(projects) == 0 {
+		embed.Description = "There are no projects yet. Create one with /project create."
+	}
+
+	for index, project := range projects {
+		if index == 25 {
+			break
+		}
+		channels := "No channels"
+		if len(project.Bindings) > 0 {
+			var mentions []string
+			for _, binding := range project.Bindings {
+				mentions = append(mentions, "<#"+binding.ChannelID+">")
+			}
+			channels = strings.Join(mentions, " ")
+		}
+		title := project.Name
+		if project.Key != "" {
+			title += " (" + project.Key + ")"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  title,
+			Value: truncateField(channels),
+		})
+	}
+
+	return embed, nil
+}
+
+// resolveProject finds the project named by a command option, or else the project
+// the channel defaults to. It returns nil when neither applies.
+func (h *CommandHandler) resolveProject(s *discordgo.Session, guildID, channelID, name string) (*projectEnt.Project, error) {
+	if name != "" {
+		project, err := h.projectController.GetProject(guildID, name)
+		if err != nil {
+			return nil, err
+		}
+		return &project, nil
+	}
+
+	return h.projectController.FindProjectForChannels(guildID, channelAncestors(s, channelID))
+}
+
+// channelAncestors lists a channel followed by its parents: the channel of a thread, then the category
+func channelAncestors(s *discordgo.Session, channelID string) []string {
+	ancestors := []string{channelID}
+	for len(ancestors) < 3 {
+		channel, err := s.State.Channel(ancestors[len(ancestors)-1])
+		if err != nil {
+			channel, err = s.Channel(ancestors[len(ancestors)-1])
+		}
+		if err != nil {
+			log.Printf("Error fetching channel %s: %v", ancestors[len(ancestors)-1], err)
+			break
+		}
+		if channel.ParentID == "" {
+			break
+		}
+		ancestors = append(ancestors, channel.ParentID)
+	}
+	return ancestors
+}
+
+// projectNames maps the primary key of every project of a guild to its name
+func (h *CommandHandler) projectNames(guildID string) map[uint]string {
+	names := make(map[uint]string)
+	projects, err := h.projectController.GetProjects(guildID)
+	if err != nil {
+		log.Printf("Error fetching projects: %v", err)
+	}
+	for _, project := range projects {
+		names[project.ID] = project.Name
+	}
+	return names
+}
+
+// handleProjectAutocomplete suggests projects matching what the user typed so far
+func (h *CommandHandler) handleProjectAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	projects, err := h.projectController.GetProjects(i.GuildID)
+	if err != nil {
+		log.Printf("Error fetching projects: %v", err)
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	query = strings.ToLower(query)
+	for _, project := range projects {
+		if len(choices) == 25 {
+			break
+		}
+		if strings.Contains(strings.ToLower(project.Name), query) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: project.Name, Value: project.Name})
+		}
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to project autocomplete: %v", err)
+	}
+}
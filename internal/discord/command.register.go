@@ -3,6 +3,11 @@ package discord
 import "github.com/bwmarrin/discordgo"
 
 func RegisterCommands(s *discordgo.Session) error {
+	// Settings commands are only shown to members who can manage the guild
+	var manageGuild int64 = discordgo.PermissionManageServer
+	minHour := 0.0
+	minWeeks := 1.0
+
 	commands := []*discordgo.ApplicationCommand{
 		{
 			Name:        "create",
@@ -11,41 +16,73 @@ func RegisterCommands(s *discordgo.Session) error {
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "title",
-					Description: "Title of the task",
-					Required:    true,
+					Description: "Title of the task, may come from a template",
+					Required:    false,
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "description",
-					Description: "Description of the task",
-					Required:    true,
+					Description: "Description of the task, may come from a template",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "priority",
+					Description:  "Priority of the task, from the server's scale",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionMentionable,
+					Name:        "executor",
+					Description: "Executor of the task, or a role to pick one from",
+					Required:    false,
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "priority",
-					Description: "Priority of the task",
+					Name:        "due",
+					Description: "Due date of the task (YYYY-MM-DD, today, tomorrow, +3d, +2w)",
 					Required:    false,
-					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{
-							Name:  "High",
-							Value: "High",
-						},
-						{
-							Name:  "Medium",
-							Value: "Medium",
-						},
-						{
-							Name:  "Low",
-							Value: "Low",
-						},
-					},
 				},
 				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "executor",
-					Description: "Executor of the task",
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "estimate",
+					Description: "Estimated work, e.g. 4h or 1h30m",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "milestone",
+					Description:  "Milestone the task belongs to",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "project",
+					Description:  "Project of the task, defaults to the project of the channel",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tags",
+					Description: "Comma separated tags, e.g. bug, backend",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "checklist",
+					Description: "Semicolon separated checklist items, e.g. Write tests; Update docs",
 					Required:    false,
 				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "template",
+					Description:  "Template to fill in the options you leave out",
+					Required:     false,
+					Autocomplete: true,
+				},
 			},
 		},
 		{
@@ -55,7 +92,27 @@ func RegisterCommands(s *discordgo.Session) error {
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "id",
-					Description: "ID of task",
+					Description: "ID of task, e.g. 42 or BE-42",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "project",
+					Description:  "Only show tasks of this project",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "status",
+					Description:  "Only show tasks in this status",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "query",
+					Description: "Filter such as status:open priority:>=medium assignee:@me due:<7d tag:backend",
 					Required:    false,
 				},
 			},
@@ -67,7 +124,7 @@ func RegisterCommands(s *discordgo.Session) error {
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "id",
-					Description: "ID of task",
+					Description: "ID of task, e.g. 42 or BE-42",
 					Required:    true,
 				},
 				{
@@ -82,43 +139,1323 @@ func RegisterCommands(s *discordgo.Session) error {
 					Description: "Description of the task",
 					Required:    false,
 				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "priority",
+					Description:  "Priority of the task, from the server's scale",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "executor",
+					Description: "Executor of the task",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "status",
+					Description:  "Status of the task, from the server's workflow",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "due",
+					Description: "Due date of the task (YYYY-MM-DD, today, tomorrow, +3d, +2w)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "estimate",
+					Description: "Estimated work, e.g. 4h or 1h30m",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "milestone",
+					Description:  "Milestone the task belongs to, or none to remove it",
+					Required:     false,
+					Autocomplete: true,
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "priority",
-					Description: "Priority of the task",
+					Name:        "tags",
+					Description: "Comma separated tags replacing the current ones, or none to clear them",
 					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "delete",
+			Description: "Delete your task by ID",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "id",
+					Description: "ID of task, e.g. 42 or BE-42",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "claim",
+			Description: "Claim an unclaimed task queued for one of your roles",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "id",
+					Description: "ID of task, e.g. 42 or BE-42",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     "assignment",
+			Description:              "Choose how tasks assigned to a role are distributed",
+			DefaultMemberPermissions: &manageGuild,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "strategy",
+					Description: "Assignment strategy",
+					Required:    true,
 					Choices: []*discordgo.ApplicationCommandOptionChoice{
 						{
-							Name:  "High",
-							Value: "High",
+							Name:  "Round-robin",
+							Value: "RoundRobin",
+						},
+						{
+							Name:  "Least open tasks",
+							Value: "LeastLoaded",
 						},
 						{
-							Name:  "Medium",
-							Value: "Medium",
+							Name:  "Random",
+							Value: "Random",
 						},
 						{
-							Name:  "Low",
-							Value: "Low",
+							Name:  "Role queue (members /claim)",
+							Value: "Queue",
 						},
 					},
 				},
+			},
+		},
+		{
+			Name:        "notify",
+			Description: "Manage how you are notified about tasks",
+			Options: []*discordgo.ApplicationCommandOption{
 				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "executor",
-					Description: "Executor of the task",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "settings",
+					Description: "Show your notification settings",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Choose how you are notified about an event",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "event",
+							Description: "Event to configure",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{
+									Name:  "Task assigned to you",
+									Value: "Assigned",
+								},
+								{
+									Name:  "Task reassigned to you",
+									Value: "Reassigned",
+								},
+								{
+									Name:  "Comment on a task you work on or watch",
+									Value: "Commented",
+								},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "delivery",
+							Description: "How the notification reaches you",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{
+									Name:  "Channel mention",
+									Value: "Mention",
+								},
+								{
+									Name:  "Direct message",
+									Value: "DM",
+								},
+								{
+									Name:  "Digest only",
+									Value: "Digest",
+								},
+								{
+									Name:  "Mute",
+									Value: "Mute",
+								},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "quiet",
+					Description: "Hold back mentions and DMs for your digest during these hours (same hour turns it off)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "start",
+							Description: "Hour quiet time starts (0-23)",
+							Required:    true,
+							MinValue:    &minHour,
+							MaxValue:    23,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "end",
+							Description: "Hour quiet time ends (0-23)",
+							Required:    true,
+							MinValue:    &minHour,
+							MaxValue:    23,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "digest",
+			Description: "Get a regular summary of your tasks",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "subscribe",
+					Description: "Receive a daily or weekly digest",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "frequency",
+							Description: "How often you receive the digest",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{
+									Name:  "Daily",
+									Value: "Daily",
+								},
+								{
+									Name:  "Weekly",
+									Value: "Weekly",
+								},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "delivery",
+							Description: "Where the digest is sent",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{
+									Name:  "Direct message",
+									Value: "DM",
+								},
+								{
+									Name:  "Digest channel",
+									Value: "Channel",
+								},
+							},
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "view",
+							Description:  "Saved view whose open tasks are added to the digest",
+							Required:     false,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "unsubscribe",
+					Description: "Stop receiving digests",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "schedule",
+					Description: "Set where and when digests are sent (Manage Server only)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel digests are posted in",
+							Required:     true,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "hour",
+							Description: "Hour digests are sent at in each member's time zone (0-23)",
+							Required:    true,
+							MinValue:    &minHour,
+							MaxValue:    23,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "weekday",
+							Description: "Day weekly digests are sent on",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Sunday", Value: 0},
+								{Name: "Monday", Value: 1},
+								{Name: "Tuesday", Value: 2},
+								{Name: "Wednesday", Value: 3},
+								{Name: "Thursday", Value: 4},
+								{Name: "Friday", Value: 5},
+								{Name: "Saturday", Value: 6},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "timezone",
+			Description: "Set the time zone used for your dates, digests and quiet hours",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Set your personal time zone",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "zone",
+							Description:  "IANA time zone, e.g. Europe/Berlin",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "clear",
+					Description: "Use the server time zone again",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "guild",
+					Description: "Set the default time zone of the server (Manage Server only)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "zone",
+							Description:  "IANA time zone, e.g. Europe/Berlin",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "track",
+			Description: "Track the time you spend on tasks",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "start",
+					Description: "Start a timer on a task",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "stop",
+					Description: "Stop your running timer",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "log",
+					Description: "Log time you spent on a task without a timer",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "duration",
+							Description: "Time spent, e.g. 1h30m",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "date",
+							Description: "Day the work was done (YYYY-MM-DD, today, yesterday); defaults to now",
+							Required:    false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "report",
+			Description: "Build reports from tracked time",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "timesheet",
+					Description: "Hours per day and task, as an embed and a CSV file",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionUser,
+							Name:        "user",
+							Description: "Member to report on; defaults to you (others need Manage Server)",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "from",
+							Description: "First day (YYYY-MM-DD, today, -7d); defaults to this Monday",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "to",
+							Description: "Last day (YYYY-MM-DD, today, -1d); defaults to this Sunday",
+							Required:    false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "stats",
+			Description: "Show statistics about the tasks of this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "weeks",
+					Description: "Number of weeks of throughput to show (default 8)",
 					Required:    false,
+					MinValue:    &minWeeks,
+					MaxValue:    52,
 				},
 			},
 		},
 		{
-			Name:        "delete",
-			Description: "Delete your task by ID",
+			Name:        "chart",
+			Description: "Draw a chart of the tasks of this server",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "id",
-					Description: "ID of task",
-					Required:    true,
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "burndown",
+					Description: "Remaining tasks due in a period against an ideal line",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "from",
+							Description: "First day (YYYY-MM-DD, today or -7d), defaults to the start of this week",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "to",
+							Description: "Last day (YYYY-MM-DD, today or +7d), defaults to the end of this week",
+							Required:    false,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "milestone",
+							Description:  "Milestone to burn down instead of tasks due in a period",
+							Required:     false,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "flow",
+					Description: "Open and done tasks at the end of every day",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "weeks",
+							Description: "Number of weeks to show (default 4)",
+							Required:    false,
+							MinValue:    &minWeeks,
+							MaxValue:    52,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "workload",
+					Description: "Open tasks of every executor",
+				},
+			},
+		},
+		{
+			Name:        "milestone",
+			Description: "Plan milestones and follow their progress",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "create",
+					Description: "Create a milestone (requires Manage Server)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name of the milestone, e.g. Sprint 12",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "start",
+							Description: "First day (YYYY-MM-DD, today or +1d)",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "end",
+							Description: "Last day (YYYY-MM-DD or +2w)",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "close",
+					Description: "Close a milestone (requires Manage Server)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "milestone",
+							Description:  "Milestone to close",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "show",
+					Description: "Show the progress of a milestone",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "milestone",
+							Description:  "Milestone to show, defaults to the current one",
+							Required:     false,
+							Autocomplete: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "project",
+			Description: "Organize tasks into projects bound to channels",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "create",
+					Description: "Create a project (requires Manage Server)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name of the project, e.g. Backend",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "key",
+							Description: "Prefix of task references, e.g. BE for BE-42",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "bind",
+					Description: "Make a channel or category default to a project (requires Manage Server)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "project",
+							Description:  "Project to bind",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel or category, defaults to this channel",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildCategory, discordgo.ChannelTypeGuildForum},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "key",
+					Description: "Set the prefix of task references of a project (requires Manage Server)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "project",
+							Description:  "Project to change",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "key",
+							Description: "Prefix of task references, e.g. BE for BE-42",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "unbind",
+					Description: "Stop a channel or category from defaulting to a project (requires Manage Server)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel or category, defaults to this channel",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildCategory, discordgo.ChannelTypeGuildForum},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List the projects and their channels",
+				},
+			},
+		},
+		{
+			Name:        "board",
+			Description: "Show the open and recently completed tasks of the server or a project",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "project",
+					Description:  "Project to show, defaults to the project of the channel",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "query",
+					Description: "Filter such as status:open priority:>=medium assignee:@me due:<7d tag:backend",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "autolink",
+			Description:              "Preview tasks referenced as #42 or BE-42 in chat messages",
+			DefaultMemberPermissions: &manageGuild,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Whether task references get a preview",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "priority",
+			Description: "Manage the priority scale of the server",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "Show the priority scale",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Replace the priority scale",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "levels",
+							Description: "Comma separated levels, the most urgent first, e.g. P0, P1, P2, P3, P4",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "style",
+					Description: "Set the color and emoji of a priority",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "level",
+							Description:  "Priority to style",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "color",
+							Description: "Hex color such as #FF0000, or none",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "emoji",
+							Description: "Emoji shown in front of the priority, or none",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "reset",
+					Description: "Go back to the High, Medium and Low scale",
+				},
+			},
+		},
+		{
+			Name:        "workflow",
+			Description: "Manage the task statuses of the server and the moves between them",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "show",
+					Description: "Show the statuses and allowed moves",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Replace the statuses of the workflow",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "states",
+							Description: "Comma separated statuses in board order, e.g. Todo, Review, QA, Done",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "terminal",
+							Description: "Status of done tasks, defaults to the last one",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "allow",
+					Description: "Allow tasks to move from one status to another",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "from",
+							Description:  "Status tasks move from",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "to",
+							Description:  "Status tasks move to",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "forbid",
+					Description: "Stop tasks from moving from one status to another",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "from",
+							Description:  "Status tasks move from",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "to",
+							Description:  "Status tasks move to",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "reset",
+					Description: "Go back to the Open and Done workflow",
+				},
+			},
+		},
+		{
+			Name:        "field",
+			Description: "Manage custom fields of tasks",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "define",
+					Description: "Add a custom field to the tasks of the server",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name of the field, e.g. Customer or Story points",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "kind",
+							Description: "Kind of values the field holds",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{
+									Name:  "Text",
+									Value: "Text",
+								},
+								{
+									Name:  "Number",
+									Value: "Number",
+								},
+								{
+									Name:  "Date",
+									Value: "Date",
+								},
+								{
+									Name:  "User",
+									Value: "User",
+								},
+								{
+									Name:  "Single select",
+									Value: "Select",
+								},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "options",
+							Description: "Comma separated options of a single-select field, e.g. Staging, Production",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Set the value of a custom field on a task",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "field",
+							Description:  "Field to set",
+							Required:     true,
+							Autocomplete: true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "value",
+							Description:  "New value; leave out to clear the field",
+							Required:     false,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List the custom fields of the server",
+				},
+			},
+		},
+		{
+			Name:        "template",
+			Description: "Manage task templates",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "save",
+					Description: "Save a task as a template",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name of the template",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List the templates of the server",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "delete",
+					Description: "Delete a template",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "template",
+							Description:  "Name of the template",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "checklist",
+			Description: "Manage the checklist of a task",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Add an item to the checklist of a task",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "item",
+							Description: "Text of the item",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "check",
+					Description: "Check or uncheck an item of the checklist of a task",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "number",
+							Description: "Number of the item",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Remove an item from the checklist of a task",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "number",
+							Description: "Number of the item",
+							Required:    true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "clone",
+			Description: "Copy a task into a new task",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "id",
+					Description: "ID of task, e.g. 42 or BE-42",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "checklist",
+					Description: "Copy the checklist, unchecked",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "comments",
+					Description: "Copy the comments",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "merge",
+			Description: "Merge a duplicate task into another task",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "source",
+					Description: "ID of the duplicate task, which is deleted",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "target",
+					Description: "ID of the task that is kept",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "move",
+			Description: "Move a task to another project",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "id",
+					Description: "ID of task, e.g. 42 or BE-42",
+					Required:    true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "project",
+					Description:  "Project to move the task to, or none to take it out of its project",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "comment",
+			Description: "Comment on tasks",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Leave a comment on a task",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "text",
+							Description: "Text of the comment",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List the comments of a task",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of task, e.g. 42 or BE-42",
+							Required:    true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "watch",
+			Description: "Start or stop watching the comments of a task",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "id",
+					Description: "ID of task, e.g. 42 or BE-42",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "search",
+			Description: "Search the titles, descriptions and comments of tasks",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "query",
+					Description: "Words to look for; quote phrases and prefix words with - to exclude them",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "view",
+			Description: "Manage saved task queries",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "save",
+					Description: "Save a task query as a view, replacing a view of the same name",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name of the view",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "query",
+							Description: "Task query, e.g. status:open assignee:@me due:<7d",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "shared",
+							Description: "Share the view with the whole server (requires Manage Server)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List your views and the server's shared views",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "run",
+					Description: "Show the tasks matching a view",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "view",
+							Description:  "Name of the view",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "delete",
+					Description: "Delete a view",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "view",
+							Description:  "Name of the view",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "pin",
+					Description: "Post a board of a view in this channel and keep it up to date (requires Manage Server)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "view",
+							Description:  "Name of the view",
+							Required:     true,
+							Autocomplete: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "bulk",
+			Description: "Close, reassign, retag, reprioritize or delete many tasks at once, after a preview",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "action",
+					Description: "What to do with the tasks",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Close", Value: "close"},
+						{Name: "Reassign", Value: "reassign"},
+						{Name: "Retag", Value: "retag"},
+						{Name: "Reprioritize", Value: "reprioritize"},
+						{Name: "Delete", Value: "delete"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tasks",
+					Description: "Tasks to change, e.g. 3,5,9-12 or BE-4,BE-7",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "query",
+					Description: "Task query selecting the tasks instead, e.g. status:open milestone:\"Sprint 3\"",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "executor",
+					Description: "New executor when reassigning",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "priority",
+					Description:  "New priority when reprioritizing",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tags",
+					Description: "Comma separated tags to add when retagging; prefix a tag with - to remove it",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "export",
+			Description: "Export the tasks of the server as a file",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "format",
+					Description: "Format of the file",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "JSON (with checklists, comments and history)", Value: "json"},
+						{Name: "CSV", Value: "csv"},
+						{Name: "Markdown", Value: "markdown"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "query",
+					Description: "Task query limiting the export, e.g. project:Backend status:open",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "import",
+			Description: "Import tasks from Trello, Todoist, GitHub or a TaskChord export (requires Manage Server)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "file",
+					Description: "Preview and import the tasks of an export file",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "source",
+							Description: "Tool the file comes from",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Trello board (JSON)", Value: "trello"},
+								{Name: "Todoist project (CSV)", Value: "todoist"},
+								{Name: "GitHub issues (JSON)", Value: "github"},
+								{Name: "TaskChord export (JSON)", Value: "taskchord"},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionAttachment,
+							Name:        "file",
+							Description: "The export file, at most 10 MB",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "map",
+					Description: "Give the tasks of a user of another tool to a member",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "User name in the other tool, e.g. a Trello or GitHub login",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionUser,
+							Name:        "user",
+							Description: "Member to map the name to; leave out to remove the mapping",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "mappings",
+					Description: "List the user mappings of the server",
+				},
+			},
+		},
+		{
+			Name:        "calendar",
+			Description: "Follow due dates and milestones in Google Calendar or Outlook",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "link",
+					Description: "Get the secret link of a calendar feed",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionString,
+							Name:         "project",
+							Description:  "Feed of every task of a project instead of your own tasks (requires Manage Server)",
+							Required:     false,
+							Autocomplete: true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "reset",
+					Description: "Revoke your calendar links so they stop working",
 				},
 			},
 		},
@@ -0,0 +1,187 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strconv"
+	"strings"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	trackEnt "taskchord/internal/pkg/track/ent"
+	"time"
+)
+
+func (h *CommandHandler) handleReportCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	switch subcommand.Name {
+	case "timesheet":
+		h.handleTimesheetReport(s, i, subcommand.Options)
+	}
+}
+
+func (h *CommandHandler) handleTimesheetReport(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+	userID := i.Interaction.Member.User.ID
+	var from, to string
+
+	for _, opt := range options {
+		switch opt.Name {
+		case "user":
+			userID = opt.UserValue(nil).ID
+		case "from":
+			from = opt.StringValue()
+		case "to":
+			to = opt.StringValue()
+		}
+	}
+
+	// Only managers may look at the hours of someone else
+	if userID != i.Interaction.Member.User.ID && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to see the timesheet of another member.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Days are those of the reported user; the period defaults to the current week
+	location := h.userController.GetLocation(guildID, userID)
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	start := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	end := start.AddDate(0, 0, 7)
+
+	var err error
+	if from != "" {
+		start, err = parseReportDate(from, location)
+	}
+	if err == nil && to != "" {
+		end, err = parseReportDate(to, location)
+		end = end.AddDate(0, 0, 1) // The last day is included
+	}
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to build the timesheet. Dates must look like 2024-12-31, today or -7d.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	timesheet, err := h.trackController.GetTimesheet(guildID, userID, start, end, location)
+	if err != nil {
+		log.Printf("Error building timesheet: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to build the timesheet. Make sure the period ends after it starts and spans at most a year.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	file, err := timesheetCSV(timesheet)
+	if err != nil {
+		log.Printf("Error writing timesheet CSV: %v", err)
+	}
+
+	data := &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{timesheetEmbed(timesheet)},
+		Flags:  discordgo.MessageFlagsEphemeral,
+	}
+	if file != nil {
+		data.Files = []*discordgo.File{file}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+// parseReportDate parses a day typed by a user into its local midnight
+func parseReportDate(value string, location *time.Location) (time.Time, error) {
+	day, err := parseDueDate(value, taskEnt.Day(time.Now().In(location)))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, location), nil
+}
+
+// timesheetEmbed renders a timesheet with one field per day
+func timesheetEmbed(timesheet trackEnt.Timesheet) *discordgo.MessageEmbed {
+	lastDay := timesheet.To.AddDate(0, 0, -1)
+	embed := &discordgo.MessageEmbed{
+		Title: "Timesheet:",
+		Description: fmt.Sprintf("<@%s> from %s to %s\n**Total: %s**",
+			timesheet.UserID, timesheet.From.Format(dueDateLayout), lastDay.Format(dueDateLayout),
+			formatDuration(time.Duration(timesheet.Seconds)*time.Second)),
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+
+	if len(timesheet.Days) == 0 {
+		embed.Description += "\nNo time was tracked in this period."
+	}
+
+	// Embeds hold at most 25 fields; the attached CSV always has every day
+	for index, day := range timesheet.Days {
+		if index == 24 && len(timesheet.Days) > 25 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  "…",
+				Value: fmt.Sprintf("%d more day(s) in the attached CSV", len(timesheet.Days)-24),
+			})
+			break
+		}
+
+		var lines []string
+		for _, task := range day.Tasks {
+			lines = append(lines, fmt.Sprintf("%s %s — %s", task.Reference, task.Title, formatDuration(time.Duration(task.Seconds)*time.Second)))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s %s — %s", day.Date.Format(dueDateLayout), day.Date.Weekday(), formatDuration(time.Duration(day.Seconds)*time.Second)),
+			Value: truncateField(strings.Join(lines, "\n")),
+		})
+	}
+
+	return embed
+}
+
+// timesheetCSV writes a timesheet as a CSV attachment with one row per day and task
+func timesheetCSV(timesheet trackEnt.Timesheet) (*discordgo.File, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	rows := [][]string{{"date", "task_id", "reference", "title", "minutes", "hours"}}
+	for _, day := range timesheet.Days {
+		for _, task := range day.Tasks {
+			rows = append(rows, []string{
+				day.Date.Format(dueDateLayout),
+				strconv.Itoa(task.TaskIdInGuild),
+				task.Reference,
+				task.Title,
+				strconv.FormatInt(task.Seconds/60, 10),
+				strconv.FormatFloat(float64(task.Seconds)/3600, 'f', 2, 64),
+			})
+		}
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return &discordgo.File{
+		Name:        fmt.Sprintf("timesheet-%s-%s.csv", timesheet.UserID, timesheet.From.Format(dueDateLayout)),
+		ContentType: "text/csv",
+		Reader:      &buffer,
+	}, nil
+}
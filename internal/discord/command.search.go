@@ -0,0 +1,50 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+)
+
+func (h *CommandHandler) handleSearchCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	query := i.ApplicationCommandData().Options[0].StringValue()
+
+	// Members find their own tasks and those of their role queues, managers find every task
+	everything := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	results, err := h.searchController.Search(i.GuildID, i.Member.User.ID, i.Member.Roles, everything, query)
+	if err != nil {
+		log.Printf("Error searching tasks: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to search tasks. Queries have at most 200 characters; please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("Search results for “%s”:", query),
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+	if len(results) == 0 {
+		embed.Description = "No tasks match your search."
+	}
+
+	for _, result := range results {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s %s · %s", result.Task.Reference(), result.Task.Title, result.Task.Status),
+			Value: truncateField(result.Snippet),
+		})
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
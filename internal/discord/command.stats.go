@@ -0,0 +1,162 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"slices"
+	"strings"
+	priorityEnt "taskchord/internal/pkg/priority/ent"
+	statsEnt "taskchord/internal/pkg/stats/ent"
+	"time"
+)
+
+// barWidth is the number of characters of the longest text bar
+const barWidth = 12
+
+func (h *CommandHandler) handleStatsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	weeks := 8
+
+	options := i.ApplicationCommandData().Options
+	if len(options) > 0 {
+		weeks = int(options[0].IntValue())
+	}
+
+	// Overdue counts follow the calendar day of the member asking
+	today := memberToday(h.userController, guildID, i.Member.User.ID)
+
+	stats, err := h.statsController.GetGuildStats(guildID, weeks, today)
+	if err != nil {
+		log.Printf("Error computing statistics: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to compute statistics. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Priorities are listed in the order of the guild's scale
+	scale, err := h.priorityController.GetScale(guildID)
+	if err != nil {
+		log.Printf("Error fetching priority scale: %v", err)
+	}
+	slices.SortStableFunc(stats.OpenByPriority, func(a, b statsEnt.GroupCount) int {
+		return scale.Rank(a.Key) - scale.Rank(b.Key)
+	})
+
+	// And statuses in the order of the guild's workflow
+	workflow, err := h.workflowController.GetWorkflow(guildID)
+	if err != nil {
+		log.Printf("Error fetching workflow: %v", err)
+	}
+	slices.SortStableFunc(stats.OpenByStatus, func(a, b statsEnt.GroupCount) int {
+		return workflow.Rank(a.Key) - workflow.Rank(b.Key)
+	})
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{statsEmbed(stats, weeks, scale, s, guildID)},
+		},
+	})
+}
+
+// statsEmbed renders guild statistics with text bar charts
+func statsEmbed(stats statsEnt.GuildStats, weeks int, scale priorityEnt.Scale, s *discordgo.Session, guildID string) *discordgo.MessageEmbed {
+	leadTime := "n/a"
+	if stats.CompletedInRange > 0 {
+		leadTime = formatLeadTime(stats.AverageLeadTime)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Server Statistics:",
+		Description: fmt.Sprintf("Open: **%d** · Overdue: **%d** · Completed in the last %d week(s): **%d**\nAverage lead time: **%s**",
+			stats.OpenCount, stats.OverdueCount, weeks, stats.CompletedInRange, leadTime),
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+
+	// Created and completed share one scale so the bars can be compared
+	var maxWeek int64
+	for _, week := range stats.Throughput {
+		maxWeek = max(maxWeek, week.Created, week.Completed)
+	}
+	var throughput []string
+	for _, week := range stats.Throughput {
+		throughput = append(throughput, fmt.Sprintf("%s\n+ %s %d\n✓ %s %d",
+			week.Week.Format("Jan 02"),
+			textBar(week.Created, maxWeek), week.Created,
+			textBar(week.Completed, maxWeek), week.Completed))
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Throughput per week (+ created, ✓ completed)",
+		Value: truncateField("```\n" + strings.Join(throughput, "\n") + "\n```"),
+	})
+
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   "Open by status",
+		Value:  groupChart(stats.OpenByStatus, func(key string) string { return key }),
+		Inline: true,
+	})
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   "Open by priority",
+		Value:  groupChart(stats.OpenByPriority, func(key string) string { return priorityLabel(scale, key) }),
+		Inline: true,
+	})
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name: "Open by executor",
+		Value: groupChart(stats.OpenByExecutor, func(key string) string {
+			if key == "" {
+				return "Unclaimed"
+			}
+			return GetNicknameFromIDWithCache(key, s, guildID)
+		}),
+		Inline: true,
+	})
+
+	return embed
+}
+
+// groupChart renders one text bar per group, labelled with the given function
+func groupChart(groups []statsEnt.GroupCount, label func(key string) string) string {
+	if len(groups) == 0 {
+		return "Nothing open!"
+	}
+
+	var maxCount int64
+	for _, group := range groups {
+		maxCount = max(maxCount, group.Count)
+	}
+
+	var lines []string
+	for _, group := range groups {
+		lines = append(lines, fmt.Sprintf("%s %d %s", textBar(group.Count, maxCount), group.Count, label(group.Key)))
+	}
+	return truncateField(strings.Join(lines, "\n"))
+}
+
+// textBar draws a bar proportional to value, where maxValue fills barWidth characters
+func textBar(value, maxValue int64) string {
+	if maxValue == 0 {
+		return strings.Repeat("░", barWidth)
+	}
+	filled := int(value * barWidth / maxValue)
+	if value > 0 && filled == 0 {
+		filled = 1 // Any activity stays visible
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+}
+
+// formatLeadTime renders a lead time in days and hours
+func formatLeadTime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days == 0 {
+		return formatDuration(d)
+	}
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
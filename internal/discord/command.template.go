@@ -0,0 +1,205 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	"time"
+)
+
+func (h *CommandHandler) handleTemplateCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	switch subcommand.Name {
+	case "save":
+		h.handleTemplateSave(s, i, subcommand.Options)
+	case "list":
+		h.handleTemplateList(s, i)
+	case "delete":
+		h.handleTemplateDelete(s, i, subcommand.Options[0].StringValue())
+	}
+}
+
+func (h *CommandHandler) handleTemplateSave(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+
+	var id, name string
+	for _, opt := range options {
+		switch opt.Name {
+		case "id":
+			id = opt.StringValue()
+		case "name":
+			name = opt.StringValue()
+		}
+	}
+
+	// Replacing a template is left to the member who saved it and to managers
+	if existing, err := h.templateController.GetTemplate(guildID, name); err == nil && !h.canManageTemplate(i, existing.UserID) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "A template with that name was saved by someone else. Pick another name.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Templates are readable by the whole server, so members who are no managers only save tasks they can see
+	task, err := h.taskController.GetTask(guildID, id)
+	manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+	if err == nil && !manager && !task.VisibleTo(userID, i.Member.Roles) {
+		err = fmt.Errorf("task with ID %s is not visible to user %s", id, userID)
+	}
+	if err == nil {
+		_, err = h.templateController.SaveTemplate(guildID, userID, name, task)
+	}
+	if err != nil {
+		log.Printf("Error saving template: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to save the template. Make sure the task exists, you wrote it, execute it or could claim it for one of your roles, and the name has at most 50 characters.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Template **%s** was saved from task **%s**. Use `/create template: %s` to create tasks like it.", name, task.Reference(), name),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleTemplateList(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	templates, err := h.templateController.GetTemplates(i.GuildID)
+	if err != nil {
+		log.Printf("Error fetching templates: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the templates. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "Templates:",
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+	if len(templates) == 0 {
+		embed.Description = "No templates yet. Save a task as one with /template save."
+	}
+
+	// Embeds hold at most 25 fields
+	for index, template := range templates {
+		if index == 25 {
+			break
+		}
+
+		lines := []string{"Title: " + template.Title, "Priority: " + template.Priority}
+		if template.RoleID != "" {
+			lines = append(lines, fmt.Sprintf("Executor: <@&%s>", template.RoleID))
+		} else if template.ExecutorID != "" {
+			lines = append(lines, fmt.Sprintf("Executor: <@%s>", template.ExecutorID))
+		}
+		if tags := template.TagNames(); len(tags) > 0 {
+			lines = append(lines, "Tags: "+strings.Join(tags, ", "))
+		}
+		if items := template.ChecklistItems(); len(items) > 0 {
+			lines = append(lines, fmt.Sprintf("Checklist: %d item(s)", len(items)))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  template.Name,
+			Value: truncateField(strings.Join(lines, "\n")),
+		})
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleTemplateDelete(s *discordgo.Session, i *discordgo.InteractionCreate, name string) {
+	template, err := h.templateController.GetTemplate(i.GuildID, name)
+	if err == nil && !h.canManageTemplate(i, template.UserID) {
+		err = fmt.Errorf("template %s was saved by someone else", name)
+	}
+	if err == nil {
+		err = h.templateController.DeleteTemplate(i.GuildID, template.Name)
+	}
+	if err != nil {
+		log.Printf("Error deleting template: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to delete the template. Only the member who saved it and members with the Manage Server permission can delete it.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Template **%s** was deleted.", template.Name),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// canManageTemplate reports whether the member may replace or delete a template saved by ownerID
+func (h *CommandHandler) canManageTemplate(i *discordgo.InteractionCreate, ownerID string) bool {
+	return ownerID == i.Member.User.ID || i.Member.Permissions&discordgo.PermissionManageServer != 0
+}
+
+// templateVariables lists the values of the variables templates may use, as seen by the member creating the task
+func (h *CommandHandler) templateVariables(s *discordgo.Session, i *discordgo.InteractionCreate) map[string]string {
+	now := time.Now().In(h.userController.GetLocation(i.GuildID, i.Member.User.ID))
+	return map[string]string{
+		"date":    now.Format(dueDateLayout),
+		"time":    now.Format("15:04"),
+		"weekday": now.Weekday().String(),
+		"user":    GetNicknameFromIDWithCache(i.Member.User.ID, s, i.GuildID),
+	}
+}
+
+// handleTemplateAutocomplete suggests the templates of the guild
+func (h *CommandHandler) handleTemplateAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	templates, err := h.templateController.GetTemplates(i.GuildID)
+	if err != nil {
+		log.Printf("Error fetching templates: %v", err)
+	}
+
+	var names []string
+	for _, template := range templates {
+		names = append(names, template.Name)
+	}
+	respondChoices(s, i, names, query)
+}
+
+// splitList splits a list typed by a user, dropping blank entries
+func splitList(value, separator string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,79 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+)
+
+func (h *CommandHandler) handleTimezoneCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	var err error
+	switch subcommand.Name {
+	case "set":
+		err = h.userController.SetTimezone(userID, subcommand.Options[0].StringValue())
+	case "clear":
+		err = h.userController.SetTimezone(userID, "")
+	case "guild":
+		// Only members who can manage the guild may change its default time zone
+		if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "You need the Manage Server permission to change the server time zone.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		err = h.guildController.SetTimezone(guildID, subcommand.Options[0].StringValue())
+	}
+
+	if err != nil {
+		log.Printf("Error updating time zone: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to update the time zone. Please pick an IANA zone such as Europe/Berlin.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Every subcommand answers with the zone now in effect
+	location := h.userController.GetLocation(guildID, userID)
+	content := fmt.Sprintf("Your time zone is **%s**.", location)
+	if subcommand.Name == "guild" {
+		content = fmt.Sprintf("The server time zone is now **%s**. Your time zone is **%s**.", subcommand.Options[0].StringValue(), location)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// handleTimezoneAutocomplete suggests IANA zones matching what the user typed so far
+func handleTimezoneAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, zone := range matchTimezones(query, 25) {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: zone, Value: zone})
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to time zone autocomplete: %v", err)
+	}
+}
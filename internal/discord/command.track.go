@@ -0,0 +1,84 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+func (h *CommandHandler) handleTrackCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := i.Interaction.Member.User.ID
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	var content string
+	switch subcommand.Name {
+	case "start":
+		task, err := h.trackController.StartTimer(guildID, userID, subcommand.Options[0].StringValue())
+		if err != nil {
+			log.Printf("Error starting timer: %v", err)
+			h.respondTrackError(s, i, "Failed to start the timer. Make sure the task exists and stop your running timer first.")
+			return
+		}
+		content = fmt.Sprintf("Timer started on task **%s %s**.", task.Reference(), task.Title)
+	case "stop":
+		task, entry, err := h.trackController.StopTimer(userID)
+		if err != nil {
+			log.Printf("Error stopping timer: %v", err)
+			h.respondTrackError(s, i, "Failed to stop the timer. You have no running timer.")
+			return
+		}
+		content = fmt.Sprintf("Timer stopped. Tracked **%s** on task **%s %s**.",
+			formatDuration(time.Duration(entry.Seconds)*time.Second), task.Reference(), task.Title)
+	case "log":
+		id := subcommand.Options[0].StringValue()
+		duration, err := time.ParseDuration(subcommand.Options[1].StringValue())
+		if err != nil {
+			h.respondTrackError(s, i, "Failed to log time. The duration must look like 1h30m.")
+			return
+		}
+
+		// Time logged for another day ends at the end of that day in the user's time zone, but never later than now
+		location := h.userController.GetLocation(guildID, userID)
+		endedAt := time.Now()
+		if len(subcommand.Options) > 2 {
+			day, err := parseDueDate(subcommand.Options[2].StringValue(), taskEnt.Day(endedAt.In(location)))
+			if err != nil {
+				h.respondTrackError(s, i, "Failed to log time. The date must look like 2024-12-31, today or yesterday.")
+				return
+			}
+			if endOfDay := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, location); endOfDay.Before(endedAt) {
+				endedAt = endOfDay
+			}
+		}
+
+		task, err := h.trackController.LogTime(guildID, userID, id, duration, endedAt)
+		if err != nil {
+			log.Printf("Error logging time: %v", err)
+			h.respondTrackError(s, i, "Failed to log time. Make sure the task exists and the duration is at most 24h.")
+			return
+		}
+		content = fmt.Sprintf("Logged **%s** on task **%s %s**.", formatDuration(duration), task.Reference(), task.Title)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// respondTrackError answers a /track command with an ephemeral error message
+func (h *CommandHandler) respondTrackError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
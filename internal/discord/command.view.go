@@ -0,0 +1,277 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	taskCtrl "taskchord/internal/pkg/task/ctrl"
+	userCtrl "taskchord/internal/pkg/user/ctrl"
+	viewEnt "taskchord/internal/pkg/view/ent"
+	workflowCtrl "taskchord/internal/pkg/workflow/ctrl"
+	"time"
+)
+
+func (h *CommandHandler) handleViewCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	switch subcommand.Name {
+	case "save":
+		h.handleViewSave(s, i, subcommand.Options)
+	case "list":
+		h.handleViewList(s, i)
+	case "run":
+		h.handleViewRun(s, i, subcommand.Options[0].StringValue())
+	case "delete":
+		h.handleViewDelete(s, i, subcommand.Options[0].StringValue())
+	case "pin":
+		h.handleViewPin(s, i, subcommand.Options[0].StringValue())
+	}
+}
+
+func (h *CommandHandler) handleViewSave(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var name, query string
+	var shared bool
+	for _, opt := range options {
+		switch opt.Name {
+		case "name":
+			name = opt.StringValue()
+		case "query":
+			query = opt.StringValue()
+		case "shared":
+			shared = opt.BoolValue()
+		}
+	}
+
+	// Shared views are managed by members who can manage the guild
+	if shared && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to save shared views.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	view, err := h.viewController.SaveView(i.GuildID, i.Member.User.ID, name, query, shared)
+	if err != nil {
+		log.Printf("Error saving view: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Failed to save the view: %v. Names have at most 50 characters.", err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	scope := "your"
+	if view.Shared() {
+		scope = "the server's"
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("View **%s** was saved to %s views. Use `/view run view: %s` to see its tasks.", view.Name, scope, view.Name),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleViewList(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	views, err := h.viewController.GetViews(i.GuildID, i.Member.User.ID)
+	if err != nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the views. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "Views:",
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+	if len(views) == 0 {
+		embed.Description = "No views yet. Save a query with /view save."
+	}
+
+	// Embeds hold at most 25 fields
+	for index, view := range views {
+		if index == 25 {
+			break
+		}
+		name := view.Name
+		if view.Shared() {
+			name += " (shared)"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  name,
+			Value: truncateField("`" + view.Query + "`"),
+		})
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleViewRun(s *discordgo.Session, i *discordgo.InteractionCreate, name string) {
+	view, err := h.viewController.GetView(i.GuildID, i.Member.User.ID, name)
+	var embed *discordgo.MessageEmbed
+	if err == nil {
+		manager := i.Member.Permissions&discordgo.PermissionManageServer != 0
+		embed, err = viewBoardEmbed(s, &h.taskController, h.workflowController, h.userController, view, i.Member.User.ID, i.Member.Roles, manager)
+	}
+	if err != nil {
+		log.Printf("Error running view: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to run the view. Make sure it exists and its query is still valid.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleViewDelete(s *discordgo.Session, i *discordgo.InteractionCreate, name string) {
+	view, err := h.viewController.GetView(i.GuildID, i.Member.User.ID, name)
+	if err == nil && view.Shared() && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		err = fmt.Errorf("view %s is shared", name)
+	}
+	if err == nil {
+		err = h.viewController.DeleteView(view)
+	}
+	if err != nil {
+		log.Printf("Error deleting view: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to delete the view. Make sure it exists; shared views can only be deleted with the Manage Server permission.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("View **%s** was deleted, along with the boards pinned from it.", view.Name),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *CommandHandler) handleViewPin(s *discordgo.Session, i *discordgo.InteractionCreate, name string) {
+	userID := i.Member.User.ID
+
+	// Pinned boards stay in the channel for everyone, so only managers post them
+	if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to pin boards.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	view, err := h.viewController.GetView(i.GuildID, userID, name)
+	var embed *discordgo.MessageEmbed
+	if err == nil {
+		embed, err = viewBoardEmbed(s, &h.taskController, h.workflowController, h.userController, view, userID, nil, true)
+	}
+	var message *discordgo.Message
+	if err == nil {
+		message, err = s.ChannelMessageSendEmbed(i.ChannelID, embed)
+	}
+	if err == nil {
+		_, err = h.viewController.AddPin(view, userID, i.ChannelID, message.ID)
+	}
+	if err != nil {
+		log.Printf("Error pinning view: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to pin the board. Make sure the view exists and the bot can post in this channel.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Pinning needs the Manage Messages permission; without it the board is still kept up to date
+	content := fmt.Sprintf("The board of view **%s** was pinned and is refreshed every few minutes. Delete the message to stop it.", view.Name)
+	if err := s.ChannelMessagePin(i.ChannelID, message.ID); err != nil {
+		log.Printf("Error pinning message in channel %s: %v", i.ChannelID, err)
+		content = fmt.Sprintf("The board of view **%s** was posted and is refreshed every few minutes, but the bot could not pin it. Delete the message to stop it.", view.Name)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// viewBoardEmbed renders the open and recently completed tasks matching a view as a board.
+// @me and relative dates in the query are resolved for the given member, who sees every task only
+// if everything is visible to them; otherwise the tasks they wrote, execute or could claim for one of their roles.
+func viewBoardEmbed(s *discordgo.Session, taskController *taskCtrl.TaskController, workflowController *workflowCtrl.WorkflowController, userController *userCtrl.UserController, view viewEnt.View, userID string, roles []string, everything bool) (*discordgo.MessageEmbed, error) {
+	today := memberToday(userController, view.GuildID, userID)
+
+	filter, err := taskController.CompileQuery(view.GuildID, userID, view.Query, today)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := taskController.GetBoard(view.GuildID, userID, roles, everything, nil, time.Now().AddDate(0, 0, -boardDoneDays), filter)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := workflowController.GetWorkflow(view.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := boardEmbed(fmt.Sprintf("View %s:", view.Name), tasks, workflow, s, view.GuildID)
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: view.Query}
+	embed.Timestamp = time.Now().Format(time.RFC3339)
+	return embed, nil
+}
+
+// handleViewAutocomplete suggests the views the member can use
+func (h *CommandHandler) handleViewAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	views, err := h.viewController.GetViews(i.GuildID, i.Member.User.ID)
+	if err != nil {
+		log.Printf("Error fetching views: %v", err)
+	}
+
+	var names []string
+	for _, view := range views {
+		names = append(names, view.Name)
+	}
+	respondChoices(s, i, names, query)
+}
@@ -0,0 +1,153 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"strings"
+	workflowEnt "taskchord/internal/pkg/workflow/ent"
+)
+
+func (h *CommandHandler) handleWorkflowCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildID := i.GuildID
+	subcommand := i.ApplicationCommandData().Options[0]
+
+	// Only members who can manage the guild may change the workflow
+	if subcommand.Name != "show" && i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You need the Manage Server permission to change the workflow.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	options := make(map[string]string)
+	for _, opt := range subcommand.Options {
+		options[opt.Name] = opt.StringValue()
+	}
+
+	var err error
+	var failure string
+	switch subcommand.Name {
+	case "set":
+		_, err = h.workflowController.SetStates(guildID, options["states"], options["terminal"])
+		failure = "Failed to set the workflow. List 2 to 20 unique statuses of at most 20 characters, separated by commas, and pick a terminal status other than the first."
+	case "allow":
+		_, err = h.workflowController.AllowTransition(guildID, options["from"], options["to"])
+		failure = "Failed to allow the move. Make sure both statuses exist, differ, and the move is not allowed yet."
+	case "forbid":
+		_, err = h.workflowController.ForbidTransition(guildID, options["from"], options["to"])
+		failure = "Failed to forbid the move. Make sure both statuses exist and the move is allowed."
+	case "reset":
+		err = h.workflowController.ResetWorkflow(guildID)
+		failure = "Failed to reset the workflow. Please try again later."
+	}
+	if err != nil {
+		log.Printf("Error updating workflow: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: failure,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Every subcommand answers with the resulting workflow
+	workflow, err := h.workflowController.GetWorkflow(guildID)
+	if err != nil {
+		log.Printf("Error fetching workflow: %v", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Failed to fetch the workflow. Please try again later.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{workflowEmbed(workflow)},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// workflowEmbed lists the states of a workflow in board order with the moves allowed from each
+func workflowEmbed(workflow workflowEnt.Workflow) *discordgo.MessageEmbed {
+	var lines []string
+	for _, state := range workflow.States {
+		line := fmt.Sprintf("%d. **%s**", state.Position+1, state.Name)
+		switch {
+		case state.Position == 0:
+			line += " (start)"
+		case state.Terminal:
+			line += " (terminal)"
+		}
+
+		var next []string
+		for _, target := range workflow.Next(state.Name) {
+			next = append(next, target.Name)
+		}
+		if len(next) == 0 {
+			next = append(next, "nowhere")
+		}
+		lines = append(lines, line+" → "+strings.Join(next, ", "))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "Workflow:",
+		Description: strings.Join(lines, "\n"),
+		Color:       0x00FF00, // Green color
+	}
+}
+
+// handleStatusAutocomplete suggests the states of the guild's workflow. When updating a task,
+// only the states it may move to are suggested.
+func (h *CommandHandler) handleStatusAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, query string) {
+	workflow, err := h.workflowController.GetWorkflow(i.GuildID)
+	if err != nil {
+		log.Printf("Error fetching workflow: %v", err)
+	}
+
+	states := workflow.States
+	if i.ApplicationCommandData().Name == "update" {
+		for _, opt := range i.ApplicationCommandData().Options {
+			if opt.Name != "id" {
+				continue
+			}
+			task, err := h.taskController.GetTask(i.GuildID, opt.StringValue())
+			if _, known := workflow.Find(string(task.Status)); err == nil && known {
+				states = workflow.Next(string(task.Status))
+			}
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	query = strings.ToLower(query)
+	for _, state := range states {
+		if len(choices) == 25 {
+			break
+		}
+		if strings.Contains(strings.ToLower(state.Name), query) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: state.Name, Value: state.Name})
+		}
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to status autocomplete: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	userCtrl "taskchord/internal/pkg/user/ctrl"
+	"time"
+)
+
+// dueDateLayout is the format users type due dates in
+const dueDateLayout = "2006-01-02"
+
+// dateTimeLayout is the format timestamps are shown in
+const dateTimeLayout = "2006-01-02 15:04"
+
+// memberToday is the current calendar day of a member in their own time zone, as midnight UTC like due dates
+func memberToday(userController *userCtrl.UserController, guildID, userID string) time.Time {
+	return taskEnt.Day(time.Now().In(userController.GetLocation(guildID, userID)))
+}
+
+// parseDueDate parses a due date typed by a user into the start of that day.
+// Besides YYYY-MM-DD it accepts "today", "tomorrow", "yesterday", "+3d" and "-2w", which are
+// resolved against today, the member's current day as returned by memberToday.
+func parseDueDate(value string, today time.Time) (*time.Time, error) {
+	dueAt, err := taskEnt.ParseDay(value, today)
+	if err != nil {
+		return nil, err
+	}
+	return &dueAt, nil
+}
+
+// formatDueDate renders a due date the same way users type it
+func formatDueDate(dueAt *time.Time) string {
+	if dueAt == nil {
+		return "None"
+	}
+	return dueAt.UTC().Format(dueDateLayout)
+}
+
+// formatDateTime renders a point in time in the reader's time zone
+func formatDateTime(t time.Time, location *time.Location) string {
+	return t.In(location).Format(dateTimeLayout) + " " + location.String()
+}
+
+// parseEstimate parses an amount of work such as "1h30m" into whole minutes
+func parseEstimate(value string) (int, error) {
+	duration, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	if duration < time.Minute {
+		return 0, fmt.Errorf("estimate %q is shorter than a minute", value)
+	}
+	return int(duration.Minutes()), nil
+}
+
+// formatDuration renders tracked or estimated time as hours and minutes, e.g. "1h30m"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	}
+}
@@ -0,0 +1,149 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+const (
+	maxLinksPerMessage  = 3                // Previews sent for a single message at most
+	linkChannelCooldown = 10 * time.Second // Time between two previews in the same channel
+	linkRepeatCooldown  = 5 * time.Minute  // Time before the same task is previewed again in a channel
+)
+
+// taskReferencePattern matches #42 and BE-42; channel mentions such as <#123> are skipped
+var taskReferencePattern = regexp.MustCompile(`(?:^|[^<\w])(#\d+|[A-Z][A-Z0-9]{0,9}-\d+)\b`)
+
+// linkLimiter keeps previews from flooding a channel
+type linkLimiter struct {
+	mu       sync.Mutex
+	channels map[string]time.Time // Last preview per channel
+	tasks    map[string]time.Time // Last preview per channel and task
+}
+
+func newLinkLimiter() *linkLimiter {
+	return &linkLimiter{
+		channels: make(map[string]time.Time),
+		tasks:    make(map[string]time.Time),
+	}
+}
+
+// allow filters the references that may be previewed in a channel now and records them
+func (l *linkLimiter) allow(channelID string, refs []string, now time.Time) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.channels[channelID]) < linkChannelCooldown {
+		return nil
+	}
+
+	var allowed []string
+	for _, ref := range refs {
+		key := channelID + "/" + ref
+		if now.Sub(l.tasks[key]) < linkRepeatCooldown {
+			continue
+		}
+		l.tasks[key] = now
+		allowed = append(allowed, ref)
+	}
+
+	if len(allowed) > 0 {
+		l.channels[channelID] = now
+	}
+
+	// Forget entries that can no longer hold anything back
+	for key, at := range l.tasks {
+		if now.Sub(at) >= linkRepeatCooldown {
+			delete(l.tasks, key)
+		}
+	}
+	for key, at := range l.channels {
+		if now.Sub(at) >= linkChannelCooldown {
+			delete(l.channels, key)
+		}
+	}
+
+	return allowed
+}
+
+// HandleMessage replies to task references in chat messages with a short preview
+func (h *CommandHandler) HandleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author == nil || m.Author.Bot || m.GuildID == "" {
+		return
+	}
+
+	refs := taskReferences(m.Content)
+	if len(refs) == 0 {
+		return
+	}
+
+	settings, err := h.guildController.GetSettings(m.GuildID)
+	if err != nil || !settings.LinkTasks {
+		return
+	}
+
+	refs = h.linkLimiter.allow(m.ChannelID, refs, time.Now())
+	if len(refs) == 0 {
+		return
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for _, ref := range refs {
+		task, err := h.taskController.GetTask(m.GuildID, ref)
+		if err != nil {
+			continue
+		}
+		embeds = append(embeds, taskPreviewEmbed(task))
+	}
+	if len(embeds) == 0 {
+		return
+	}
+
+	_, err = s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Embeds:          embeds,
+		Reference:       m.Reference(),
+		AllowedMentions: &discordgo.MessageAllowedMentions{}, // Previews never ping anyone
+	})
+	if err != nil {
+		log.Printf("Error sending task preview in channel %s: %v", m.ChannelID, err)
+	}
+}
+
+// taskReferences lists the distinct task references of a message, in order of appearance
+func taskReferences(content string) []string {
+	var refs []string
+	for _, match := range taskReferencePattern.FindAllStringSubmatch(content, -1) {
+		ref := strings.TrimPrefix(match[1], "#")
+		if _, _, err := ent.ParseReference(ref); err != nil || slices.Contains(refs, ref) {
+			continue
+		}
+		refs = append(refs, ref)
+		if len(refs) == maxLinksPerMessage {
+			break
+		}
+	}
+	return refs
+}
+
+// taskPreviewEmbed renders the compact preview of a referenced task
+func taskPreviewEmbed(task ent.Task) *discordgo.MessageEmbed {
+	executor := "None"
+	if task.ExecutorID != "" {
+		executor = fmt.Sprintf("<@%s>", task.ExecutorID)
+	} else if task.RoleID != "" {
+		executor = fmt.Sprintf("<@&%s> (unclaimed)", task.RoleID)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       task.Reference() + " " + task.Title,
+		Description: fmt.Sprintf("Status: %s · Executor: %s · Due: %s", task.Status, executor, formatDueDate(task.DueAt)),
+		Color:       0x00FF00, // Green color
+	}
+}
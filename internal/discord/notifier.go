@@ -0,0 +1,69 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"taskchord/internal/pkg/notify/ctrl"
+	"taskchord/internal/pkg/notify/ent"
+	userCtrl "taskchord/internal/pkg/user/ctrl"
+	"time"
+)
+
+// Notifier delivers every outgoing notification according to the recipient's preferences
+type Notifier struct {
+	notifyController *ctrl.NotifyController
+	userController   *userCtrl.UserController
+}
+
+// NewNotifier creates a new notifier
+func NewNotifier(notifyController *ctrl.NotifyController, userController *userCtrl.UserController) *Notifier {
+	return &Notifier{notifyController: notifyController, userController: userController}
+}
+
+// Notify tells a user about an event that happened in a channel.
+// The message should read naturally after a mention, e.g. "task **BE-1 Foo** was assigned to you".
+func (n *Notifier) Notify(s *discordgo.Session, guildID, channelID, userID string, event ent.Event, message string) {
+	preferences, err := n.notifyController.GetPreferences(userID)
+	if err != nil {
+		log.Printf("Error fetching notification preferences for user %s: %v", userID, err)
+		preferences = map[ent.Event]ent.Delivery{event: ent.Mention}
+	}
+	delivery := preferences[event]
+
+	// Quiet hours hold back anything that would interrupt the user
+	if delivery == ent.Mention || delivery == ent.DM {
+		quiet, err := n.notifyController.GetQuietHours(userID)
+		if err != nil {
+			log.Printf("Error fetching quiet hours for user %s: %v", userID, err)
+		} else if quiet != nil && quiet.Contains(time.Now().In(n.userController.GetLocation(guildID, userID)).Hour()) {
+			delivery = ent.DigestOnly
+		}
+	}
+
+	switch delivery {
+	case ent.Mute:
+		return
+	case ent.DigestOnly:
+		err = n.notifyController.AddPending(guildID, userID, event, fmt.Sprintf("In <#%s>: %s", channelID, message))
+	case ent.DM:
+		var channel *discordgo.Channel
+		channel, err = s.UserChannelCreate(userID)
+		if err == nil {
+			_, err = s.ChannelMessageSend(channel.ID, fmt.Sprintf("In <#%s>: %s", channelID, message))
+		}
+	default:
+		_, err = s.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>, %s", userID, message))
+	}
+
+	if err != nil {
+		log.Printf("Error delivering %s notification to user %s: %v", event, userID, err)
+	}
+}
+
+// Broadcast posts a message addressed to a whole channel, such as a role mention
+func (n *Notifier) Broadcast(s *discordgo.Session, channelID, message string) {
+	if _, err := s.ChannelMessageSend(channelID, message); err != nil {
+		log.Printf("Error broadcasting to channel %s: %v", channelID, err)
+	}
+}
@@ -0,0 +1,52 @@
+package discord
+
+import (
+	"sync"
+	"time"
+)
+
+// pendingTTL is how long buttons waiting on a reply keep working, as long as Discord lets the reply be edited
+const pendingTTL = 15 * time.Minute
+
+type pendingEntry[T any] struct {
+	value     T
+	userID    string // Member who may act on the entry
+	createdAt time.Time
+}
+
+// pendingStore keeps work that waits for a member to click a button, by the ID of the interaction that started it
+type pendingStore[T any] struct {
+	mu      sync.Mutex
+	entries map[string]pendingEntry[T]
+}
+
+func newPendingStore[T any]() *pendingStore[T] {
+	return &pendingStore[T]{entries: make(map[string]pendingEntry[T])}
+}
+
+// put stores a value for a member and forgets the entries whose buttons stopped working
+func (p *pendingStore[T]) put(id, userID string, value T, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, entry := range p.entries {
+		if now.Sub(entry.createdAt) >= pendingTTL {
+			delete(p.entries, key)
+		}
+	}
+	p.entries[id] = pendingEntry[T]{value: value, userID: userID, createdAt: now}
+}
+
+// take removes and returns the value stored for a member, so each entry is acted on only once
+func (p *pendingStore[T]) take(id, userID string, now time.Time) (T, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, found := p.entries[id]
+	if !found || entry.userID != userID || now.Sub(entry.createdAt) >= pendingTTL {
+		var zero T
+		return zero, false
+	}
+	delete(p.entries, id)
+	return entry.value, true
+}
@@ -0,0 +1,362 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log"
+	"slices"
+	"strings"
+	digestCtrl "taskchord/internal/pkg/digest/ctrl"
+	digestEnt "taskchord/internal/pkg/digest/ent"
+	guildCtrl "taskchord/internal/pkg/guild/ctrl"
+	guildEnt "taskchord/internal/pkg/guild/ent"
+	taskCtrl "taskchord/internal/pkg/task/ctrl"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	trackCtrl "taskchord/internal/pkg/track/ctrl"
+	userCtrl "taskchord/internal/pkg/user/ctrl"
+	viewCtrl "taskchord/internal/pkg/view/ctrl"
+	workflowCtrl "taskchord/internal/pkg/workflow/ctrl"
+	"time"
+)
+
+// pinRefreshInterval is how often pinned boards are brought up to date
+const pinRefreshInterval = 10 * time.Minute
+
+// digestAttempts is how many times a digest is tried before its period is given up,
+// so a member who closed their DMs is not retried every minute
+const digestAttempts = 3
+
+// Scheduler runs the bot's periodic jobs, such as sending digests
+type Scheduler struct {
+	session            *discordgo.Session
+	digestController   *digestCtrl.DigestController
+	guildController    *guildCtrl.GuildController
+	userController     *userCtrl.UserController
+	trackController    *trackCtrl.TrackController
+	taskController     *taskCtrl.TaskController
+	workflowController *workflowCtrl.WorkflowController
+	viewController     *viewCtrl.ViewController
+	idleLimit          time.Duration
+	lastPinRefresh     time.Time
+	digestFailures     map[string]int // Failed attempts per user and period, only touched by the scheduler goroutine
+	stop               chan struct{}
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(session *discordgo.Session, digestController *digestCtrl.DigestController, guildController *guildCtrl.GuildController, userController *userCtrl.UserController, trackController *trackCtrl.TrackController, taskController *taskCtrl.TaskController, workflowController *workflowCtrl.WorkflowController, viewController *viewCtrl.ViewController, idleLimit time.Duration) *Scheduler {
+	return &Scheduler{
+		session:            session,
+		digestController:   digestController,
+		guildController:    guildController,
+		userController:     userController,
+		trackController:    trackController,
+		taskController:     taskController,
+		workflowController: workflowController,
+		viewController:     viewController,
+		idleLimit:          idleLimit,
+		digestFailures:     make(map[string]int),
+		stop:               make(chan struct{}),
+	}
+}
+
+// Start runs the scheduled jobs every minute until Stop is called
+func (sc *Scheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			sc.sendDigests(time.Now())
+			sc.stopIdleTimers()
+			if time.Since(sc.lastPinRefresh) >= pinRefreshInterval {
+				sc.refreshPins()
+				sc.lastPinRefresh = time.Now()
+			}
+
+			select {
+			case <-ticker.C:
+			case <-sc.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduled jobs
+func (sc *Scheduler) Stop() {
+	close(sc.stop)
+}
+
+// sendDigests sends every digest whose scheduled time has come and that was not sent yet
+func (sc *Scheduler) sendDigests(now time.Time) {
+	subscriptions, err := sc.digestController.GetSubscriptions()
+	if err != nil {
+		log.Printf("Error fetching digest subscriptions: %v", err)
+		return
+	}
+
+	settingsByGuild := make(map[string]guildEnt.Settings)
+	for _, subscription := range subscriptions {
+		settings, found := settingsByGuild[subscription.GuildID]
+		if !found {
+			settings, err = sc.guildController.GetSettings(subscription.GuildID)
+			if err != nil {
+				log.Printf("Error fetching settings of guild %s: %v", subscription.GuildID, err)
+				continue
+			}
+			settingsByGuild[subscription.GuildID] = settings
+		}
+
+		// Digests follow the member's own day, falling back to the guild's time zone
+		local := now.In(sc.userController.GetLocation(subscription.GuildID, subscription.UserID))
+		if local.Hour() < settings.DigestHour {
+			continue
+		}
+		if subscription.Frequency == digestEnt.Weekly && local.Weekday() != settings.DigestWeekday {
+			continue
+		}
+
+		// Claiming the period first keeps restarts from sending the same digest twice
+		period := fmt.Sprintf("%s %s", subscription.Frequency, local.Format(dueDateLayout))
+		claimed, err := sc.digestController.ClaimRun(subscription.GuildID, subscription.UserID, period)
+		if err != nil {
+			log.Printf("Error claiming digest for user %s: %v", subscription.UserID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		// A digest that failed to go out is released, so the next tick tries it again
+		today := taskEnt.Day(local)
+		key := fmt.Sprintf("%s %s %s", subscription.GuildID, subscription.UserID, period)
+		if sc.sendDigest(subscription, settings, today, now) {
+			delete(sc.digestFailures, key)
+			continue
+		}
+		sc.digestFailures[key]++
+		if sc.digestFailures[key] >= digestAttempts {
+			log.Printf("Giving up on %s digest for user %s after %d attempts", period, subscription.UserID, digestAttempts)
+			delete(sc.digestFailures, key)
+			continue
+		}
+		sc.digestController.ReleaseRun(subscription.GuildID, subscription.UserID, period)
+	}
+}
+
+// sendDigest collects and delivers one digest, reporting whether it was delivered
+func (sc *Scheduler) sendDigest(subscription digestEnt.Subscription, settings guildEnt.Settings, today time.Time, now time.Time) bool {
+	digest, err := sc.digestController.Collect(subscription, today, now)
+	if err != nil {
+		log.Printf("Error collecting digest for user %s: %v", subscription.UserID, err)
+		return false
+	}
+
+	// A subscribed view adds the open tasks the subscriber can see that match it
+	if subscription.ViewID != nil {
+		if err := sc.collectView(&digest, subscription, today, now); err != nil {
+			log.Printf("Error collecting view for digest of user %s: %v", subscription.UserID, err)
+		}
+	}
+
+	// Nothing to report is not worth a message, but still counts as sent
+	if !digest.IsEmpty() {
+		channelID := settings.DigestChannel
+		content := ""
+		if subscription.Delivery == digestEnt.DM || channelID == "" {
+			channel, err := sc.session.UserChannelCreate(subscription.UserID)
+			if err != nil {
+				log.Printf("Error opening DM with user %s: %v", subscription.UserID, err)
+				return false
+			}
+			channelID = channel.ID
+		} else {
+			content = fmt.Sprintf("<@%s>", subscription.UserID)
+		}
+
+		_, err = sc.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Content: content,
+			Embeds:  []*discordgo.MessageEmbed{digestEmbed(subscription, digest)},
+		})
+		if err != nil {
+			log.Printf("Error sending digest to user %s: %v", subscription.UserID, err)
+			return false
+		}
+	}
+
+	if err := sc.digestController.MarkSent(subscription, digest, now); err != nil {
+		log.Printf("Error marking digest as sent for user %s: %v", subscription.UserID, err)
+	}
+	return true
+}
+
+// collectView fills the view section of a digest
+func (sc *Scheduler) collectView(digest *digestEnt.Digest, subscription digestEnt.Subscription, today time.Time, now time.Time) error {
+	view, err := sc.viewController.GetViewByID(*subscription.ViewID)
+	if err != nil {
+		return err
+	}
+	filter, err := sc.taskController.CompileQuery(subscription.GuildID, subscription.UserID, view.Query, today)
+	if err != nil {
+		return err
+	}
+
+	// The subscriber sees the tasks they could see with /view run, as they are a member now
+	roles, manager, err := memberAccess(sc.session, subscription.GuildID, subscription.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Nothing is completed after now, so the board holds open tasks only
+	tasks, err := sc.taskController.GetBoard(subscription.GuildID, subscription.UserID, roles, manager, nil, now, filter)
+	if err != nil {
+		return err
+	}
+	digest.ViewName = view.Name
+	digest.View = tasks
+	return nil
+}
+
+// memberAccess finds the roles of a member and whether they have the Manage Server permission,
+// for jobs that run outside an interaction. Administrators and the owner of the guild have it as well.
+func memberAccess(s *discordgo.Session, guildID, userID string) ([]string, bool, error) {
+	member, err := s.State.Member(guildID, userID)
+	if err != nil {
+		if member, err = s.GuildMember(guildID, userID); err != nil {
+			return nil, false, err
+		}
+	}
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		if guild, err = s.Guild(guildID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if guild.OwnerID == userID {
+		return member.Roles, true, nil
+	}
+	for _, role := range guild.Roles {
+		// The @everyone role shares the ID of the guild
+		if role.ID != guildID && !slices.Contains(member.Roles, role.ID) {
+			continue
+		}
+		if role.Permissions&(discordgo.PermissionManageServer|discordgo.PermissionAdministrator) != 0 {
+			return member.Roles, true, nil
+		}
+	}
+	return member.Roles, false, nil
+}
+
+// refreshPins re-renders every pinned board and forgets those whose message was deleted
+func (sc *Scheduler) refreshPins() {
+	pins, err := sc.viewController.GetPins()
+	if err != nil {
+		log.Printf("Error fetching pinned boards: %v", err)
+		return
+	}
+
+	for _, pin := range pins {
+		// Only managers pin boards, so pinned boards show every task matching the view
+		embed, err := viewBoardEmbed(sc.session, sc.taskController, sc.workflowController, sc.userController, pin.View, pin.UserID, nil, true)
+		if err != nil {
+			log.Printf("Error rendering pinned board %d: %v", pin.ID, err)
+			continue
+		}
+
+		_, err = sc.session.ChannelMessageEditEmbed(pin.ChannelID, pin.MessageID, embed)
+		var restErr *discordgo.RESTError
+		if errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == 404 {
+			if err := sc.viewController.DeletePin(pin); err != nil {
+				log.Printf("Error deleting pinned board %d: %v", pin.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("Error refreshing pinned board %d: %v", pin.ID, err)
+		}
+	}
+}
+
+// stopIdleTimers stops timers that were left running longer than the idle limit
+func (sc *Scheduler) stopIdleTimers() {
+	entries, err := sc.trackController.StopIdleTimers(sc.idleLimit)
+	if err != nil {
+		log.Printf("Error stopping idle timers: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		log.Printf("Stopped idle timer of user %s on task %d after %s", entry.UserID, entry.TaskID, sc.idleLimit)
+	}
+}
+
+// digestEmbed renders a digest with one field per non-empty section
+func digestEmbed(subscription digestEnt.Subscription, digest digestEnt.Digest) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("Your %s Digest:", subscription.Frequency),
+		Color:  0x00FF00, // Green color
+		Fields: []*discordgo.MessageEmbedField{},
+	}
+
+	sections := []struct {
+		name  string
+		tasks []taskEnt.Task
+	}{
+		{"Due today", digest.DueToday},
+		{"Overdue", digest.Overdue},
+		{"Newly assigned", digest.NewlyAssigned},
+		{"Completed", digest.Completed},
+	}
+	for _, section := range sections {
+		if len(section.tasks) == 0 {
+			continue
+		}
+		var lines []string
+		for _, task := range section.tasks {
+			line := "**" + task.Reference() + " " + task.Title + "**"
+			if task.DueAt != nil {
+				line += " (due " + formatDueDate(task.DueAt) + ")"
+			}
+			lines = append(lines, line)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  section.name,
+			Value: truncateField(strings.Join(lines, "\n")),
+		})
+	}
+
+	if len(digest.View) > 0 {
+		var lines []string
+		for _, task := range digest.View {
+			lines = append(lines, "**"+task.Reference()+" "+task.Title+"**")
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "View " + digest.ViewName,
+			Value: truncateField(strings.Join(lines, "\n")),
+		})
+	}
+
+	if len(digest.Notifications) > 0 {
+		var lines []string
+		for _, pending := range digest.Notifications {
+			lines = append(lines, pending.Content)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Notifications",
+			Value: truncateField(strings.Join(lines, "\n")),
+		})
+	}
+
+	return embed
+}
+
+// truncateField shortens text to fit in an embed field value
+func truncateField(value string) string {
+	const limit = 1024
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit-3] + "..."
+}
@@ -0,0 +1,46 @@
+package ctrl
+
+import (
+	"log"
+	"taskchord/internal/pkg/calendar/ent"
+	"taskchord/internal/pkg/calendar/svc"
+)
+
+type CalendarController struct {
+	calendarService *svc.CalendarService
+}
+
+// NewCalendarController creates a new calendar controller
+func NewCalendarController(calendarService *svc.CalendarService) *CalendarController {
+	return &CalendarController{calendarService: calendarService}
+}
+
+// GetFeed retrieves the feed of a member for their own tasks or a project, creating it on first use
+func (c *CalendarController) GetFeed(guildID, userID string, projectID *uint) (ent.Feed, error) {
+	feed, err := c.calendarService.GetFeed(guildID, userID, projectID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Feed{}, err
+	}
+	return feed, nil
+}
+
+// ResetFeeds revokes every feed of a member in a guild; the next link gets a new URL
+func (c *CalendarController) ResetFeeds(guildID, userID string) (int64, error) {
+	count, err := c.calendarService.ResetFeeds(guildID, userID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetCalendar builds the calendar behind a feed token
+func (c *CalendarController) GetCalendar(token string) (ent.Calendar, error) {
+	calendar, err := c.calendarService.GetCalendar(token)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Calendar{}, err
+	}
+	return calendar, nil
+}
@@ -0,0 +1,30 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Feed is a secret calendar URL of a member, with their own due dates or those of a project, for GORM
+type Feed struct {
+	gorm.Model
+	GuildID   string `gorm:"not null;index" json:"guild_id"`
+	UserID    string `gorm:"not null;index" json:"user_id"`
+	ProjectID *uint  `json:"project_id"`                                     // Project whose tasks the feed shows, nil for the member's own tasks
+	Token     string `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"` // Secret part of the URL
+}
+
+// Event is a whole-day entry of a calendar, such as a due date or the end of a milestone
+type Event struct {
+	UID         string // Stays the same across refreshes, so calendar apps update the event instead of adding one
+	Summary     string
+	Description string
+	Day         time.Time // Midnight UTC of the day
+	UpdatedAt   time.Time
+}
+
+// Calendar is what a feed shows
+type Calendar struct {
+	Name   string
+	Events []Event
+}
@@ -0,0 +1,114 @@
+package svc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"taskchord/internal/pkg/calendar/ent"
+	milestoneEnt "taskchord/internal/pkg/milestone/ent"
+	projectEnt "taskchord/internal/pkg/project/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+)
+
+type CalendarService struct {
+	db gossiper.Database
+}
+
+// NewCalendarService initializes a new calendar service
+func NewCalendarService(db gossiper.Database) *CalendarService {
+	return &CalendarService{db: db}
+}
+
+// GetFeed retrieves the feed of a member for their own tasks or a project, creating it on first use
+func (s *CalendarService) GetFeed(guildID, userID string, projectID *uint) (ent.Feed, error) {
+	var feeds []ent.Feed
+	query := s.db.GetDB().Where("guild_id = ? AND user_id = ?", guildID, userID)
+	if projectID != nil {
+		query = query.Where("project_id = ?", *projectID)
+	} else {
+		query = query.Where("project_id IS NULL")
+	}
+	if err := query.Limit(1).Find(&feeds).Error; err != nil {
+		return ent.Feed{}, err
+	}
+	if len(feeds) > 0 {
+		return feeds[0], nil
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return ent.Feed{}, err
+	}
+	feed := ent.Feed{GuildID: guildID, UserID: userID, ProjectID: projectID, Token: token}
+	err = s.db.GetDB().Create(&feed).Error
+	return feed, err
+}
+
+// ResetFeeds revokes every feed of a member in a guild and reports how many there were
+func (s *CalendarService) ResetFeeds(guildID, userID string) (int64, error) {
+	result := s.db.GetDB().Unscoped().Where("guild_id = ? AND user_id = ?", guildID, userID).Delete(&ent.Feed{})
+	return result.RowsAffected, result.Error
+}
+
+// GetCalendar builds the calendar of a feed: the due dates of open tasks and the last days of open milestones.
+// A member's own feed has the tasks they created or execute; a project feed every task of the project.
+func (s *CalendarService) GetCalendar(token string) (ent.Calendar, error) {
+	db := s.db.GetDB()
+
+	var feed ent.Feed
+	if err := db.Where("token = ?", token).First(&feed).Error; err != nil {
+		return ent.Calendar{}, err
+	}
+
+	calendar := ent.Calendar{Name: "TaskChord: my tasks", Events: []ent.Event{}}
+	query := db.Where("guild_id = ? AND due_at IS NOT NULL AND completed_at IS NULL", feed.GuildID)
+	if feed.ProjectID != nil {
+		var project projectEnt.Project
+		if err := db.First(&project, *feed.ProjectID).Error; err != nil {
+			return ent.Calendar{}, err
+		}
+		calendar.Name = "TaskChord: " + project.Name
+		query = query.Where("project_id = ?", *feed.ProjectID)
+	} else {
+		query = query.Where("(user_id = ? OR executor_id = ?)", feed.UserID, feed.UserID)
+	}
+
+	var tasks []taskEnt.Task
+	if err := query.Order("due_at ASC").Find(&tasks).Error; err != nil {
+		return ent.Calendar{}, err
+	}
+	for _, task := range tasks {
+		calendar.Events = append(calendar.Events, ent.Event{
+			UID:         fmt.Sprintf("task-%d@taskchord", task.ID),
+			Summary:     task.Reference() + " " + task.Title,
+			Description: fmt.Sprintf("Status: %s\nPriority: %s\n\n%s", task.Status, task.Priority, task.Description),
+			Day:         *task.DueAt,
+			UpdatedAt:   task.UpdatedAt,
+		})
+	}
+
+	var milestones []milestoneEnt.Milestone
+	if err := db.Where("guild_id = ? AND closed_at IS NULL", feed.GuildID).Order("end_date ASC").Find(&milestones).Error; err != nil {
+		return ent.Calendar{}, err
+	}
+	for _, milestone := range milestones {
+		calendar.Events = append(calendar.Events, ent.Event{
+			UID:         fmt.Sprintf("milestone-%d@taskchord", milestone.ID),
+			Summary:     "Milestone " + milestone.Name + " ends",
+			Description: fmt.Sprintf("Milestone %s runs from %s to %s.", milestone.Name, milestone.StartDate.Format("2006-01-02"), milestone.EndDate.Format("2006-01-02")),
+			Day:         milestone.EndDate,
+			UpdatedAt:   milestone.UpdatedAt,
+		})
+	}
+	return calendar, nil
+}
+
+// newToken makes a secret that cannot be guessed
+func newToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,342 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"unicode/utf8"
+)
+
+const (
+	width        = 800
+	height       = 450
+	marginTop    = 40
+	marginRight  = 20
+	marginBottom = 60
+	marginLeft   = 50
+	gridLines    = 5
+	maxXLabels   = 10
+)
+
+var (
+	background = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	axisColor  = color.RGBA{0x60, 0x60, 0x60, 0xFF}
+	gridColor  = color.RGBA{0xE0, 0xE0, 0xE0, 0xFF}
+	textColor  = color.RGBA{0x20, 0x20, 0x20, 0xFF}
+
+	// Palette holds the default series colors, matching the green of the bot's embeds first
+	Palette = []color.RGBA{
+		{0x00, 0xB0, 0x00, 0xFF},
+		{0x33, 0x66, 0xCC, 0xFF},
+		{0xDC, 0x39, 0x12, 0xFF},
+		{0xFF, 0x99, 0x00, 0xFF},
+		{0x99, 0x00, 0x99, 0xFF},
+	}
+)
+
+// Series is one named row of values, one per label
+type Series struct {
+	Name   string
+	Values []float64
+	Color  color.RGBA
+	Dashed bool
+}
+
+// canvas is a chart image with its plot area
+type canvas struct {
+	img  *image.RGBA
+	plot image.Rectangle
+	max  float64
+}
+
+func newCanvas(title string, left int, maxValue float64) *canvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+
+	c := &canvas{
+		img:  img,
+		plot: image.Rect(left, marginTop, width-marginRight, height-marginBottom),
+		max:  niceMax(maxValue),
+	}
+	c.text(title, (width-textWidth(title))/2, 24)
+	return c
+}
+
+// Line renders series as lines over the labels, such as a burndown
+func Line(title string, labels []string, series []Series) (*bytes.Buffer, error) {
+	c := newCanvas(title, marginLeft, seriesMax(series, false))
+	c.valueGrid()
+	c.xLabels(labels)
+
+	for _, s := range series {
+		for i := 1; i < len(s.Values); i++ {
+			x0, y0 := c.point(i-1, len(labels), s.Values[i-1])
+			x1, y1 := c.point(i, len(labels), s.Values[i])
+			c.line(x0, y0, x1, y1, s.Color, s.Dashed)
+		}
+	}
+
+	c.axes()
+	c.legend(series)
+	return c.encode()
+}
+
+// StackedArea renders series stacked on top of each other, the first one at the bottom
+func StackedArea(title string, labels []string, series []Series) (*bytes.Buffer, error) {
+	c := newCanvas(title, marginLeft, seriesMax(series, true))
+	c.valueGrid()
+	c.xLabels(labels)
+
+	if len(labels) > 0 {
+		lower := make([]float64, len(labels))
+		for _, s := range series {
+			upper := make([]float64, len(labels))
+			for i := range upper {
+				upper[i] = lower[i] + valueAt(s.Values, i)
+			}
+			c.band(lower, upper, s.Color)
+			lower = upper
+		}
+	}
+
+	c.axes()
+	c.legend(series)
+	return c.encode()
+}
+
+// Bar renders one horizontal bar per label, such as the workload of each member
+func Bar(title string, labels []string, values []float64, barColor color.RGBA) (*bytes.Buffer, error) {
+	// Labels sit left of the bars, so the plot starts after the longest one
+	const maxLabel = 20
+	left := marginLeft
+	names := make([]string, len(labels))
+	for i, label := range labels {
+		names[i] = label
+		// The font draws a glyph per character, so labels are counted and cut in characters
+		if utf8.RuneCountInString(label) > maxLabel {
+			names[i] = string([]rune(label)[:maxLabel-1]) + "~"
+		}
+		left = max(left, textWidth(names[i])+16)
+	}
+
+	maxValue := 0.0
+	for _, value := range values {
+		maxValue = math.Max(maxValue, value)
+	}
+	c := newCanvas(title, left, maxValue)
+
+	// Value grid runs vertically for horizontal bars
+	for i := 0; i <= gridLines; i++ {
+		x := c.plot.Min.X + c.plot.Dx()*i/gridLines
+		c.line(x, c.plot.Min.Y, x, c.plot.Max.Y, gridColor, false)
+		label := formatValue(c.max * float64(i) / gridLines)
+		c.text(label, x-textWidth(label)/2, c.plot.Max.Y+18)
+	}
+
+	if len(names) > 0 {
+		slot := c.plot.Dy() / len(names)
+		for i, label := range names {
+			top := c.plot.Min.Y + slot*i + slot/5
+			bottom := c.plot.Min.Y + slot*(i+1) - slot/5
+			right := c.plot.Min.X + int(float64(c.plot.Dx())*valueAt(values, i)/c.max)
+			draw.Draw(c.img, image.Rect(c.plot.Min.X, top, right, max(bottom, top+1)), &image.Uniform{C: barColor}, image.Point{}, draw.Src)
+			c.text(label, c.plot.Min.X-textWidth(label)-8, (top+bottom)/2+4)
+			c.text(formatValue(valueAt(values, i)), right+4, (top+bottom)/2+4)
+		}
+	}
+
+	c.axes()
+	return c.encode()
+}
+
+// point converts a label index and value into pixel coordinates
+func (c *canvas) point(index, count int, value float64) (int, int) {
+	x := c.plot.Min.X
+	if count > 1 {
+		x += c.plot.Dx() * index / (count - 1)
+	}
+	y := c.plot.Max.Y - int(float64(c.plot.Dy())*value/c.max)
+	return x, y
+}
+
+// band fills the area between two stacked rows of values
+func (c *canvas) band(lower, upper []float64, fill color.RGBA) {
+	count := len(lower)
+	for x := c.plot.Min.X; x <= c.plot.Max.X; x++ {
+		// Interpolate between the two labels around this column
+		position := 0.0
+		if count > 1 {
+			position = float64(x-c.plot.Min.X) / float64(c.plot.Dx()) * float64(count-1)
+		}
+		i := min(int(position), count-1)
+		j := min(i+1, count-1)
+		fraction := position - float64(i)
+
+		low := lower[i] + (lower[j]-lower[i])*fraction
+		high := upper[i] + (upper[j]-upper[i])*fraction
+		_, yLow := c.point(0, 1, low)
+		_, yHigh := c.point(0, 1, high)
+		for y := yHigh; y < yLow; y++ {
+			c.img.Set(x, y, fill)
+		}
+	}
+}
+
+// valueGrid draws horizontal grid lines with their values
+func (c *canvas) valueGrid() {
+	for i := 0; i <= gridLines; i++ {
+		y := c.plot.Max.Y - c.plot.Dy()*i/gridLines
+		c.line(c.plot.Min.X, y, c.plot.Max.X, y, gridColor, false)
+		label := formatValue(c.max * float64(i) / gridLines)
+		c.text(label, c.plot.Min.X-textWidth(label)-6, y+4)
+	}
+}
+
+// xLabels writes labels under the plot, skipping some when there are too many to fit
+func (c *canvas) xLabels(labels []string) {
+	step := (len(labels) + maxXLabels - 1) / maxXLabels
+	for i := 0; i < len(labels); i += max(step, 1) {
+		x, _ := c.point(i, len(labels), 0)
+		c.text(labels[i], x-textWidth(labels[i])/2, c.plot.Max.Y+18)
+	}
+}
+
+func (c *canvas) axes() {
+	c.line(c.plot.Min.X, c.plot.Min.Y, c.plot.Min.X, c.plot.Max.Y, axisColor, false)
+	c.line(c.plot.Min.X, c.plot.Max.Y, c.plot.Max.X, c.plot.Max.Y, axisColor, false)
+}
+
+// legend lists the series under the x labels
+func (c *canvas) legend(series []Series) {
+	x := c.plot.Min.X
+	y := height - 18
+	for _, s := range series {
+		draw.Draw(c.img, image.Rect(x, y-9, x+10, y+1), &image.Uniform{C: s.Color}, image.Point{}, draw.Src)
+		c.text(s.Name, x+14, y)
+		x += textWidth(s.Name) + 34
+	}
+}
+
+// line draws a two pixel wide line using Bresenham's algorithm
+func (c *canvas) line(x0, y0, x1, y1 int, stroke color.RGBA, dashed bool) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	err := dx + dy
+
+	for step := 0; ; step++ {
+		if !dashed || (step/6)%2 == 0 {
+			c.img.Set(x0, y0, stroke)
+			c.img.Set(x0, y0+1, stroke)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// text writes a string with its baseline at y
+func (c *canvas) text(value string, x, y int) {
+	drawer := &font.Drawer{
+		Dst:  c.img,
+		Src:  &image.Uniform{C: textColor},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(value)
+}
+
+func (c *canvas) encode() (*bytes.Buffer, error) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, c.img); err != nil {
+		return nil, err
+	}
+	return &buffer, nil
+}
+
+func textWidth(value string) int {
+	return font.MeasureString(basicfont.Face7x13, value).Round()
+}
+
+// niceMax rounds the top of the value axis up so grid lines fall on round numbers
+func niceMax(value float64) float64 {
+	if value <= gridLines {
+		return gridLines
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(value/gridLines)))
+	for _, factor := range []float64{1, 2, 2.5, 5, 10} {
+		if step := factor * magnitude; step*gridLines >= value {
+			return step * gridLines
+		}
+	}
+	return value
+}
+
+func seriesMax(series []Series, stacked bool) float64 {
+	result := 0.0
+	for i := 0; ; i++ {
+		total, found := 0.0, false
+		for _, s := range series {
+			if i >= len(s.Values) {
+				continue
+			}
+			found = true
+			if stacked {
+				total += s.Values[i]
+			} else {
+				total = math.Max(total, s.Values[i])
+			}
+		}
+		if !found {
+			return result
+		}
+		result = math.Max(result, total)
+	}
+}
+
+func valueAt(values []float64, index int) float64 {
+	if index < len(values) {
+		return values[index]
+	}
+	return 0
+}
+
+func formatValue(value float64) string {
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.1f", value)
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func sign(value int) int {
+	switch {
+	case value > 0:
+		return 1
+	case value < 0:
+		return -1
+	}
+	return 0
+}
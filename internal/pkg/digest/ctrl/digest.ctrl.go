@@ -0,0 +1,90 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"taskchord/internal/pkg/digest/ent"
+	"taskchord/internal/pkg/digest/svc"
+	"time"
+)
+
+type DigestController struct {
+	digestService *svc.DigestService
+}
+
+// NewDigestController creates a new digest controller
+func NewDigestController(digestService *svc.DigestService) *DigestController {
+	return &DigestController{digestService: digestService}
+}
+
+// Subscribe validates and stores a user's opt-in to digests of a guild, optionally with a saved view
+func (c *DigestController) Subscribe(guildID, userID, frequency, delivery string, viewID *uint) error {
+	validFrequencies := map[string]bool{string(ent.Daily): true, string(ent.Weekly): true}
+	if !validFrequencies[frequency] {
+		log.Println("Controller error: Invalid digest frequency")
+		return fmt.Errorf("invalid digest frequency")
+	}
+
+	validDeliveries := map[string]bool{string(ent.DM): true, string(ent.Channel): true}
+	if !validDeliveries[delivery] {
+		log.Println("Controller error: Invalid digest delivery")
+		return fmt.Errorf("invalid digest delivery")
+	}
+
+	err := c.digestService.Subscribe(guildID, userID, ent.Frequency(frequency), ent.Delivery(delivery), viewID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// Unsubscribe opts a user out of digests of a guild
+func (c *DigestController) Unsubscribe(guildID, userID string) error {
+	err := c.digestService.Unsubscribe(guildID, userID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// GetSubscriptions retrieves every digest subscription
+func (c *DigestController) GetSubscriptions() ([]ent.Subscription, error) {
+	return c.digestService.GetSubscriptions()
+}
+
+// ClaimRun records that a digest is being sent, returning false if it already was
+func (c *DigestController) ClaimRun(guildID, userID, period string) (bool, error) {
+	return c.digestService.ClaimRun(guildID, userID, period)
+}
+
+// ReleaseRun forgets the claim on a period after a digest failed to go out
+func (c *DigestController) ReleaseRun(guildID, userID, period string) error {
+	err := c.digestService.ReleaseRun(guildID, userID, period)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// Collect gathers the digest of a subscription for the given local day.
+// The day is a date at midnight UTC, the same way due dates are stored.
+func (c *DigestController) Collect(subscription ent.Subscription, today time.Time, now time.Time) (ent.Digest, error) {
+	// The first digest covers one period back
+	since := now.AddDate(0, 0, -1)
+	if subscription.Frequency == ent.Weekly {
+		since = now.AddDate(0, 0, -7)
+	}
+	if subscription.LastSentAt != nil {
+		since = *subscription.LastSentAt
+	}
+
+	return c.digestService.Collect(subscription, today, since)
+}
+
+// MarkSent records that a digest was delivered
+func (c *DigestController) MarkSent(subscription ent.Subscription, digest ent.Digest, sentAt time.Time) error {
+	return c.digestService.MarkSent(subscription, digest, sentAt)
+}
@@ -0,0 +1,60 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+// Frequency represents how often a user receives a digest.
+type Frequency string
+
+const (
+	Daily  Frequency = "Daily"
+	Weekly Frequency = "Weekly"
+)
+
+// Delivery represents where a digest is sent.
+type Delivery string
+
+const (
+	DM      Delivery = "DM"
+	Channel Delivery = "Channel"
+)
+
+// Subscription represents a user's opt-in to digests of a guild
+type Subscription struct {
+	gorm.Model
+	GuildID    string     `gorm:"not null;uniqueIndex:idx_subscription" json:"guild_id"`
+	UserID     string     `gorm:"not null;uniqueIndex:idx_subscription" json:"user_id"`
+	Frequency  Frequency  `gorm:"type:varchar(20);not null" json:"frequency"`
+	Delivery   Delivery   `gorm:"type:varchar(20);not null" json:"delivery"`
+	LastSentAt *time.Time `json:"last_sent_at"` // Start of the period covered by the next digest
+	ViewID     *uint      `json:"view_id"`      // Saved view whose open tasks are added to the digest, nil if none
+}
+
+// Run records a digest that was sent, so that restarts never send the same digest twice
+type Run struct {
+	gorm.Model
+	GuildID string `gorm:"not null;uniqueIndex:idx_run" json:"guild_id"`
+	UserID  string `gorm:"not null;uniqueIndex:idx_run" json:"user_id"`
+	Period  string `gorm:"not null;uniqueIndex:idx_run" json:"period"` // Frequency and local date, e.g. "Daily 2024-12-31"
+}
+
+// Digest holds everything a user is told about in one digest
+type Digest struct {
+	DueToday      []taskEnt.Task
+	Overdue       []taskEnt.Task
+	NewlyAssigned []taskEnt.Task
+	Completed     []taskEnt.Task
+	Notifications []notifyEnt.Pending
+	ViewName      string         // Name of the subscribed view, empty if none
+	View          []taskEnt.Task // Open tasks matching the subscribed view
+}
+
+// IsEmpty reports whether there is nothing to tell the user
+func (d Digest) IsEmpty() bool {
+	return len(d.DueToday) == 0 && len(d.Overdue) == 0 && len(d.NewlyAssigned) == 0 &&
+		len(d.Completed) == 0 && len(d.Notifications) == 0 && len(d.View) == 0
+}
@@ -0,0 +1,126 @@
+package svc
+
+import (
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"taskchord/internal/pkg/digest/ent"
+	notifyEnt "taskchord/internal/pkg/notify/ent"
+	"time"
+)
+
+type DigestService struct {
+	db gossiper.Database
+}
+
+// NewDigestService initializes a new digest service
+func NewDigestService(db gossiper.Database) *DigestService {
+	return &DigestService{db: db}
+}
+
+// Subscribe opts a user into digests of a guild, replacing any previous subscription.
+// The digest also lists the open tasks of the given view, unless viewID is nil.
+func (s *DigestService) Subscribe(guildID, userID string, frequency ent.Frequency, delivery ent.Delivery, viewID *uint) error {
+	subscription := ent.Subscription{GuildID: guildID, UserID: userID}
+	return s.db.GetDB().
+		Where("guild_id = ? AND user_id = ?", guildID, userID).
+		Assign(map[string]interface{}{"frequency": frequency, "delivery": delivery, "view_id": viewID}).
+		FirstOrCreate(&subscription).Error
+}
+
+// Unsubscribe opts a user out of digests of a guild
+func (s *DigestService) Unsubscribe(guildID, userID string) error {
+	return s.db.GetDB().Unscoped().
+		Where("guild_id = ? AND user_id = ?", guildID, userID).
+		Delete(&ent.Subscription{}).Error
+}
+
+// GetSubscriptions retrieves every digest subscription across all guilds
+func (s *DigestService) GetSubscriptions() ([]ent.Subscription, error) {
+	var subscriptions []ent.Subscription
+	err := s.db.GetDB().Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// ClaimRun records that a digest is being sent for a period.
+// It returns false if that digest was already sent, e.g. before a restart.
+func (s *DigestService) ClaimRun(guildID, userID, period string) (bool, error) {
+	result := s.db.GetDB().
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ent.Run{GuildID: guildID, UserID: userID, Period: period})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseRun forgets the claim on a period, so a digest that failed to go out is tried again
+func (s *DigestService) ReleaseRun(guildID, userID, period string) error {
+	return s.db.GetDB().Unscoped().
+		Where("guild_id = ? AND user_id = ? AND period = ?", guildID, userID, period).
+		Delete(&ent.Run{}).Error
+}
+
+// Collect gathers the digest of a user: tasks due on the given day, overdue tasks,
+// and tasks assigned or completed since the last digest, plus held back notifications
+func (s *DigestService) Collect(subscription ent.Subscription, today time.Time, since time.Time) (ent.Digest, error) {
+	var digest ent.Digest
+	db := s.db.GetDB()
+	mine := db.Where("guild_id = ? AND executor_id = ?", subscription.GuildID, subscription.UserID)
+
+	err := db.Where(mine).
+		Where("completed_at IS NULL AND due_at = ?", today).
+		Order("task_id_in_guild ASC").
+		Find(&digest.DueToday).Error
+	if err != nil {
+		return digest, err
+	}
+
+	err = db.Where(mine).
+		Where("completed_at IS NULL AND due_at < ?", today).
+		Order("due_at ASC").
+		Find(&digest.Overdue).Error
+	if err != nil {
+		return digest, err
+	}
+
+	err = db.Where(mine).
+		Where("user_id <> ? AND created_at > ?", subscription.UserID, since).
+		Order("task_id_in_guild ASC").
+		Find(&digest.NewlyAssigned).Error
+	if err != nil {
+		return digest, err
+	}
+
+	err = db.Where(mine).
+		Where("completed_at > ?", since).
+		Order("completed_at ASC").
+		Find(&digest.Completed).Error
+	if err != nil {
+		return digest, err
+	}
+
+	err = db.Where("guild_id = ? AND user_id = ? AND delivered_at IS NULL", subscription.GuildID, subscription.UserID).
+		Order("created_at ASC").
+		Find(&digest.Notifications).Error
+	return digest, err
+}
+
+// MarkSent moves the subscription past the sent digest and marks its notifications as delivered
+func (s *DigestService) MarkSent(subscription ent.Subscription, digest ent.Digest, sentAt time.Time) error {
+	return s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&subscription).Update("last_sent_at", sentAt).Error
+		if err != nil {
+			return err
+		}
+
+		if len(digest.Notifications) == 0 {
+			return nil
+		}
+		ids := make([]uint, 0, len(digest.Notifications))
+		for _, pending := range digest.Notifications {
+			ids = append(ids, pending.ID)
+		}
+		return tx.Model(&notifyEnt.Pending{}).Where("id IN ?", ids).Update("delivered_at", sentAt).Error
+	})
+}
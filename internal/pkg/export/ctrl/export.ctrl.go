@@ -0,0 +1,27 @@
+package ctrl
+
+import (
+	"gorm.io/gorm"
+	"log"
+	"taskchord/internal/pkg/export/ent"
+	"taskchord/internal/pkg/export/svc"
+)
+
+type ExportController struct {
+	exportService *svc.ExportService
+}
+
+// NewExportController creates a new export controller
+func NewExportController(exportService *svc.ExportService) *ExportController {
+	return &ExportController{exportService: exportService}
+}
+
+// Export collects the tasks of a guild that a member may see and that match the filters
+func (c *ExportController) Export(guildID, userID string, roles []string, everything bool, filters ...func(*gorm.DB) *gorm.DB) (ent.Document, error) {
+	document, err := c.exportService.Export(guildID, userID, roles, everything, filters...)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Document{}, err
+	}
+	return document, nil
+}
@@ -0,0 +1,73 @@
+package ent
+
+import (
+	"time"
+)
+
+// Version is the version of the export format, raised whenever a change would confuse older imports
+const Version = 1
+
+// Format is the kind of file an export is written as
+type Format string
+
+const (
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	Markdown Format = "markdown"
+)
+
+// Document is everything exported from a guild. Its JSON form is the TaskChord export format.
+type Document struct {
+	Version    int       `json:"version"`
+	GuildID    string    `json:"guild_id"`
+	ExportedAt time.Time `json:"exported_at"`
+	Fields     []string  `json:"fields"` // Names of the custom fields of the guild
+	Tasks      []Task    `json:"tasks"`
+}
+
+// Task is an exported task. Users are Discord IDs, and projects and milestones are referred to by name.
+type Task struct {
+	Number      int               `json:"number"`    // Number of the task within the guild
+	Reference   string            `json:"reference"` // Such as BE-42 or #42
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Status      string            `json:"status"`
+	Priority    string            `json:"priority"`
+	AuthorID    string            `json:"author_id"`
+	ExecutorID  string            `json:"executor_id,omitempty"`
+	RoleID      string            `json:"role_id,omitempty"`
+	Project     string            `json:"project,omitempty"`
+	Milestone   string            `json:"milestone,omitempty"`
+	DueAt       *time.Time        `json:"due_at,omitempty"`
+	Estimate    int               `json:"estimate,omitempty"` // Minutes
+	Tags        []string          `json:"tags"`
+	Checklist   []ChecklistItem   `json:"checklist"`
+	Fields      map[string]string `json:"fields"` // Custom field values by field name
+	Watchers    []string          `json:"watchers"`
+	Comments    []Comment         `json:"comments"`
+	History     []Event           `json:"history"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+// ChecklistItem is an exported checklist item
+type ChecklistItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// Comment is an exported comment
+type Comment struct {
+	AuthorID  string    `json:"author_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Event is something that happened to a task, oldest first: its creation, time tracked on it and its completion
+type Event struct {
+	At      time.Time `json:"at"`
+	Kind    string    `json:"kind"` // created, tracked or completed
+	UserID  string    `json:"user_id,omitempty"`
+	Minutes int64     `json:"minutes,omitempty"` // Time tracked
+}
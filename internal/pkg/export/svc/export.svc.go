@@ -0,0 +1,199 @@
+package svc
+
+import (
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"sort"
+	"taskchord/internal/pkg/export/ent"
+	fieldEnt "taskchord/internal/pkg/field/ent"
+	milestoneEnt "taskchord/internal/pkg/milestone/ent"
+	projectEnt "taskchord/internal/pkg/project/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	trackEnt "taskchord/internal/pkg/track/ent"
+	"time"
+)
+
+// batchSize is how many tasks are read at once, so large guilds are exported without loading every row together
+const batchSize = 500
+
+type ExportService struct {
+	db gossiper.Database
+}
+
+// NewExportService initializes a new export service
+func NewExportService(db gossiper.Database) *ExportService {
+	return &ExportService{db: db}
+}
+
+// Export collects the tasks of a guild matching the filters along with their checklists, custom fields,
+// watchers, comments and history. Unless everything is visible, only the tasks a member authored,
+// executes or could claim for one of their roles are exported.
+func (s *ExportService) Export(guildID, userID string, roles []string, everything bool, filters ...func(*gorm.DB) *gorm.DB) (ent.Document, error) {
+	document := ent.Document{Version: ent.Version, GuildID: guildID, ExportedAt: time.Now().UTC(), Fields: []string{}, Tasks: []ent.Task{}}
+	db := s.db.GetDB()
+
+	query := db.Preload("Tags").
+		Preload("Checklist", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Scopes(taskEnt.Visible(userID, roles, everything)).
+		Where("guild_id = ?", guildID)
+	for _, filter := range filters {
+		query = query.Scopes(filter)
+	}
+
+	projects, err := s.projectNames(guildID)
+	if err != nil {
+		return ent.Document{}, err
+	}
+	milestones, err := s.milestoneNames(guildID)
+	if err != nil {
+		return ent.Document{}, err
+	}
+
+	var definitions []fieldEnt.Definition
+	if err := db.Where("guild_id = ?", guildID).Order("name").Find(&definitions).Error; err != nil {
+		return ent.Document{}, err
+	}
+	fieldNames := make(map[uint]string)
+	for _, definition := range definitions {
+		fieldNames[definition.ID] = definition.Name
+		document.Fields = append(document.Fields, definition.Name)
+	}
+
+	// FindInBatches pages by primary key, which need not follow the guild numbers, so tasks are sorted afterwards
+	var tasks []taskEnt.Task
+	err = query.FindInBatches(&tasks, batchSize, func(tx *gorm.DB, batch int) error {
+		exported, err := s.exportTasks(tasks, projects, milestones, fieldNames)
+		if err != nil {
+			return err
+		}
+		document.Tasks = append(document.Tasks, exported...)
+		return nil
+	}).Error
+	if err != nil {
+		return ent.Document{}, err
+	}
+	sort.Slice(document.Tasks, func(i, j int) bool { return document.Tasks[i].Number < document.Tasks[j].Number })
+	return document, nil
+}
+
+// exportTasks turns a batch of tasks into their exported form
+func (s *ExportService) exportTasks(tasks []taskEnt.Task, projects, milestones map[uint]string, fieldNames map[uint]string) ([]ent.Task, error) {
+	db := s.db.GetDB()
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	var comments []taskEnt.Comment
+	if err := db.Where("task_id IN ?", ids).Order("created_at ASC").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	var watchers []taskEnt.Watcher
+	if err := db.Where("task_id IN ?", ids).Order("created_at ASC").Find(&watchers).Error; err != nil {
+		return nil, err
+	}
+	var values []fieldEnt.Value
+	if err := db.Where("task_id IN ?", ids).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	var entries []trackEnt.Entry
+	if err := db.Where("task_id IN ? AND ended_at IS NOT NULL", ids).Order("started_at ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	exported := make([]ent.Task, 0, len(tasks))
+	index := make(map[uint]int)
+	for _, task := range tasks {
+		item := ent.Task{
+			Number:      task.TaskIdInGuild,
+			Reference:   task.Reference(),
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      string(task.Status),
+			Priority:    string(task.Priority),
+			AuthorID:    task.UserID,
+			ExecutorID:  task.ExecutorID,
+			RoleID:      task.RoleID,
+			DueAt:       task.DueAt,
+			Estimate:    task.Estimate,
+			Tags:        []string{},
+			Checklist:   []ent.ChecklistItem{},
+			Fields:      make(map[string]string),
+			Watchers:    []string{},
+			Comments:    []ent.Comment{},
+			History:     []ent.Event{{At: task.CreatedAt, Kind: "created", UserID: task.UserID}},
+			CreatedAt:   task.CreatedAt,
+			UpdatedAt:   task.UpdatedAt,
+			CompletedAt: task.CompletedAt,
+		}
+		if task.ProjectID != nil {
+			item.Project = projects[*task.ProjectID]
+		}
+		if task.MilestoneID != nil {
+			item.Milestone = milestones[*task.MilestoneID]
+		}
+		for _, tag := range task.Tags {
+			item.Tags = append(item.Tags, tag.Name)
+		}
+		for _, checklistItem := range task.Checklist {
+			item.Checklist = append(item.Checklist, ent.ChecklistItem{Text: checklistItem.Text, Done: checklistItem.Done})
+		}
+		index[task.ID] = len(exported)
+		exported = append(exported, item)
+	}
+
+	for _, comment := range comments {
+		item := &exported[index[comment.TaskID]]
+		item.Comments = append(item.Comments, ent.Comment{AuthorID: comment.UserID, Content: comment.Content, CreatedAt: comment.CreatedAt})
+	}
+	for _, watcher := range watchers {
+		item := &exported[index[watcher.TaskID]]
+		item.Watchers = append(item.Watchers, watcher.UserID)
+	}
+	for _, value := range values {
+		if name, found := fieldNames[value.DefinitionID]; found {
+			exported[index[value.TaskID]].Fields[name] = value.Value
+		}
+	}
+	for _, entry := range entries {
+		item := &exported[index[entry.TaskID]]
+		item.History = append(item.History, ent.Event{At: entry.StartedAt, Kind: "tracked", UserID: entry.UserID, Minutes: entry.Seconds / 60})
+	}
+
+	for position := range exported {
+		item := &exported[position]
+		if item.CompletedAt != nil {
+			item.History = append(item.History, ent.Event{At: *item.CompletedAt, Kind: "completed"})
+		}
+		sort.SliceStable(item.History, func(a, b int) bool {
+			return item.History[a].At.Before(item.History[b].At)
+		})
+	}
+	return exported, nil
+}
+
+// projectNames maps the projects of a guild to their names
+func (s *ExportService) projectNames(guildID string) (map[uint]string, error) {
+	var projects []projectEnt.Project
+	if err := s.db.GetDB().Where("guild_id = ?", guildID).Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string)
+	for _, project := range projects {
+		names[project.ID] = project.Name
+	}
+	return names, nil
+}
+
+// milestoneNames maps the milestones of a guild to their names
+func (s *ExportService) milestoneNames(guildID string) (map[uint]string, error) {
+	var milestones []milestoneEnt.Milestone
+	if err := s.db.GetDB().Where("guild_id = ?", guildID).Find(&milestones).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string)
+	for _, milestone := range milestones {
+		names[milestone.ID] = milestone.Name
+	}
+	return names, nil
+}
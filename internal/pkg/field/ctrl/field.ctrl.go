@@ -0,0 +1,121 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"taskchord/internal/pkg/field/ent"
+	"taskchord/internal/pkg/field/svc"
+)
+
+// maxChoices keeps every option of a single-select field selectable, as autocomplete offers at most 25 choices
+const maxChoices = 25
+
+type FieldController struct {
+	fieldService *svc.FieldService
+}
+
+// NewFieldController creates a new field controller
+func NewFieldController(fieldService *svc.FieldService) *FieldController {
+	return &FieldController{fieldService: fieldService}
+}
+
+// DefineField validates and stores a custom field. Single-select fields need comma separated options.
+func (c *FieldController) DefineField(guildID, name, kind, options string) (ent.Definition, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 50 {
+		log.Println("Controller error: Field name must have between 1 and 50 characters")
+		return ent.Definition{}, fmt.Errorf("field name must have between 1 and 50 characters")
+	}
+
+	fieldKind := ent.Kind(kind)
+	switch fieldKind {
+	case ent.Text, ent.Number, ent.Date, ent.User, ent.Select:
+	default:
+		log.Println("Controller error: Invalid field kind")
+		return ent.Definition{}, fmt.Errorf("invalid field kind %s", kind)
+	}
+
+	var choices []string
+	if fieldKind == ent.Select {
+		for _, choice := range strings.Split(options, ",") {
+			choice = strings.TrimSpace(choice)
+			if choice == "" || len(choice) > 100 {
+				log.Println("Controller error: Options must have between 1 and 100 characters")
+				return ent.Definition{}, fmt.Errorf("options must have between 1 and 100 characters")
+			}
+			for _, other := range choices {
+				if strings.EqualFold(other, choice) {
+					log.Println("Controller error: Options must be unique")
+					return ent.Definition{}, fmt.Errorf("option %s is listed twice", choice)
+				}
+			}
+			choices = append(choices, choice)
+		}
+		if len(choices) < 2 || len(choices) > maxChoices {
+			log.Println("Controller error: A single-select field must have between 2 and 25 options")
+			return ent.Definition{}, fmt.Errorf("a single-select field must have between 2 and %d options", maxChoices)
+		}
+	} else if strings.TrimSpace(options) != "" {
+		log.Println("Controller error: Only single-select fields have options")
+		return ent.Definition{}, fmt.Errorf("only single-select fields have options")
+	}
+
+	definition, err := c.fieldService.DefineField(guildID, name, fieldKind, choices)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Definition{}, err
+	}
+	return definition, nil
+}
+
+// GetDefinition finds a custom field by name
+func (c *FieldController) GetDefinition(guildID, name string) (ent.Definition, error) {
+	definition, err := c.fieldService.GetDefinition(guildID, name)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Definition{}, err
+	}
+	return definition, nil
+}
+
+// GetDefinitions lists the custom fields of a guild
+func (c *FieldController) GetDefinitions(guildID string) ([]ent.Definition, error) {
+	definitions, err := c.fieldService.GetDefinitions(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// SetValue checks a value against the kind of the named field and stores it for a task.
+// Dates must already be in YYYY-MM-DD form; an empty value removes the field from the task.
+func (c *FieldController) SetValue(guildID string, taskID uint, name, value string) (ent.TaskValue, error) {
+	definition, err := c.GetDefinition(guildID, name)
+	if err != nil {
+		return ent.TaskValue{}, err
+	}
+
+	value, err = definition.Normalize(strings.TrimSpace(value))
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.TaskValue{}, err
+	}
+
+	if err := c.fieldService.SetValue(definition.ID, taskID, value); err != nil {
+		log.Println("Controller error:", err)
+		return ent.TaskValue{}, err
+	}
+	return ent.TaskValue{Definition: definition, Value: value}, nil
+}
+
+// GetValues lists the custom field values of a task
+func (c *FieldController) GetValues(guildID string, taskID uint) ([]ent.TaskValue, error) {
+	values, err := c.fieldService.GetValues(guildID, taskID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,107 @@
+package ent
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind represents the type of values a custom field holds.
+type Kind string
+
+const (
+	Text   Kind = "Text"
+	Number Kind = "Number"
+	Date   Kind = "Date"
+	User   Kind = "User"
+	Select Kind = "Select"
+)
+
+// String method for the Kind type, to provide string representation of each kind.
+func (k Kind) String() string {
+	switch k {
+	case Text:
+		return "Text"
+	case Number:
+		return "Number"
+	case Date:
+		return "Date"
+	case User:
+		return "User"
+	case Select:
+		return "Single select"
+	default:
+		return "Unknown"
+	}
+}
+
+// Definition describes a custom field that the tasks of a guild can have, for GORM
+type Definition struct {
+	gorm.Model
+	GuildID string `gorm:"not null;uniqueIndex:idx_field_name" json:"guild_id"`
+	Name    string `gorm:"type:varchar(50);not null;uniqueIndex:idx_field_name" json:"name"`
+	Kind    Kind   `gorm:"type:varchar(20);not null" json:"kind"`
+	Options string `gorm:"type:text" json:"options"` // Comma separated choices of single-select fields
+}
+
+// Choices lists the options of a single-select field
+func (d Definition) Choices() []string {
+	if d.Options == "" {
+		return nil
+	}
+	return strings.Split(d.Options, ",")
+}
+
+// Normalize validates a value for the field and brings it into its stored form
+func (d Definition) Normalize(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch d.Kind {
+	case Text:
+		if len(value) > 1000 {
+			return "", fmt.Errorf("text values must have at most 1000 characters")
+		}
+	case Number:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a number", value)
+		}
+		value = strconv.FormatFloat(number, 'f', -1, 64)
+	case Date:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", fmt.Errorf("%s is not a date", value)
+		}
+	case User:
+		// Accept mentions such as <@123> and <@!123> as well as plain IDs
+		value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(value, "<@"), "!"), ">")
+		if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+			return "", fmt.Errorf("%s is not a user", value)
+		}
+	case Select:
+		for _, choice := range d.Choices() {
+			if strings.EqualFold(choice, value) {
+				return choice, nil
+			}
+		}
+		return "", fmt.Errorf("%s is not an option of field %s", value, d.Name)
+	}
+	return value, nil
+}
+
+// Value stores the value of a custom field for one task, for GORM
+type Value struct {
+	gorm.Model
+	DefinitionID uint   `gorm:"not null;uniqueIndex:idx_field_value" json:"definition_id"`
+	TaskID       uint   `gorm:"not null;uniqueIndex:idx_field_value;index" json:"task_id"` // Primary key of the task
+	Value        string `gorm:"type:text;not null" json:"value"`                           // Normalized by kind: numbers in plain notation, dates as YYYY-MM-DD, users as IDs
+}
+
+// TaskValue pairs a field definition with the value a task has for it
+type TaskValue struct {
+	Definition Definition
+	Value      string
+}
@@ -0,0 +1,85 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"strings"
+	"taskchord/internal/pkg/field/ent"
+)
+
+type FieldService struct {
+	db gossiper.Database
+}
+
+// NewFieldService initializes a new field service
+func NewFieldService(db gossiper.Database) *FieldService {
+	return &FieldService{db: db}
+}
+
+// DefineField adds a custom field to the tasks of a guild
+func (s *FieldService) DefineField(guildID, name string, kind ent.Kind, options []string) (ent.Definition, error) {
+	if _, err := s.GetDefinition(guildID, name); err == nil {
+		return ent.Definition{}, fmt.Errorf("field %s already exists", name)
+	}
+
+	definition := ent.Definition{GuildID: guildID, Name: name, Kind: kind, Options: strings.Join(options, ",")}
+	err := s.db.GetDB().Create(&definition).Error
+	return definition, err
+}
+
+// GetDefinition finds a custom field of a guild by name, ignoring case
+func (s *FieldService) GetDefinition(guildID, name string) (ent.Definition, error) {
+	var definition ent.Definition
+	err := s.db.GetDB().Where("guild_id = ? AND LOWER(name) = LOWER(?)", guildID, name).First(&definition).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return definition, fmt.Errorf("field %s does not exist", name)
+	}
+	return definition, err
+}
+
+// GetDefinitions lists the custom fields of a guild, by name
+func (s *FieldService) GetDefinitions(guildID string) ([]ent.Definition, error) {
+	var definitions []ent.Definition
+	err := s.db.GetDB().Where("guild_id = ?", guildID).Order("name").Find(&definitions).Error
+	return definitions, err
+}
+
+// SetValue stores the value of a field for a task; an empty value removes it
+func (s *FieldService) SetValue(definitionID, taskID uint, value string) error {
+	return s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("definition_id = ? AND task_id = ?", definitionID, taskID).
+			Delete(&ent.Value{}).Error
+		if err != nil || value == "" {
+			return err
+		}
+		return tx.Create(&ent.Value{DefinitionID: definitionID, TaskID: taskID, Value: value}).Error
+	})
+}
+
+// GetValues lists the fields a task has a value for, by field name
+func (s *FieldService) GetValues(guildID string, taskID uint) ([]ent.TaskValue, error) {
+	definitions, err := s.GetDefinitions(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []ent.Value
+	if err := s.db.GetDB().Where("task_id = ?", taskID).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	byDefinition := make(map[uint]string, len(values))
+	for _, value := range values {
+		byDefinition[value.DefinitionID] = value.Value
+	}
+
+	var taskValues []ent.TaskValue
+	for _, definition := range definitions {
+		if value, ok := byDefinition[definition.ID]; ok {
+			taskValues = append(taskValues, ent.TaskValue{Definition: definition, Value: value})
+		}
+	}
+	return taskValues, nil
+}
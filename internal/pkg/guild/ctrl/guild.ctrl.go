@@ -0,0 +1,88 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"taskchord/internal/pkg/guild/ent"
+	"taskchord/internal/pkg/guild/svc"
+	"time"
+)
+
+type GuildController struct {
+	guildService *svc.GuildService
+}
+
+// NewGuildController creates a new guild controller
+func NewGuildController(guildService *svc.GuildService) *GuildController {
+	return &GuildController{guildService: guildService}
+}
+
+// GetSettings retrieves the settings of a guild
+func (c *GuildController) GetSettings(guildID string) (ent.Settings, error) {
+	return c.guildService.GetSettings(guildID)
+}
+
+// SetAssignStrategy validates and stores the role assignment strategy of a guild
+func (c *GuildController) SetAssignStrategy(guildID, strategy string) error {
+	validStrategies := map[string]bool{
+		string(ent.RoundRobin):  true,
+		string(ent.LeastLoaded): true,
+		string(ent.Random):      true,
+		string(ent.Queue):       true,
+	}
+	if !validStrategies[strategy] {
+		log.Println("Controller error: Invalid assignment strategy")
+		return fmt.Errorf("invalid assignment strategy")
+	}
+
+	err := c.guildService.SetAssignStrategy(guildID, ent.AssignStrategy(strategy))
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// SetDigestSchedule validates and stores where and when the digests of a guild are sent
+func (c *GuildController) SetDigestSchedule(guildID, channelID string, hour, weekday int) error {
+	if hour < 0 || hour > 23 {
+		log.Println("Controller error: Digest hour must be between 0 and 23")
+		return fmt.Errorf("digest hour must be between 0 and 23")
+	}
+	if weekday < 0 || weekday > 6 {
+		log.Println("Controller error: Digest weekday must be between 0 (Sunday) and 6 (Saturday)")
+		return fmt.Errorf("digest weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+
+	err := c.guildService.SetDigestSchedule(guildID, channelID, hour, time.Weekday(weekday))
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// SetTimezone validates and stores the default time zone of a guild
+func (c *GuildController) SetTimezone(guildID, timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		log.Println("Controller error: Invalid time zone:", err)
+		return fmt.Errorf("invalid time zone %q", timezone)
+	}
+
+	err := c.guildService.SetTimezone(guildID, timezone)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// SetLinkTasks turns previews of task references in chat messages on or off
+func (c *GuildController) SetLinkTasks(guildID string, enabled bool) error {
+	err := c.guildService.SetLinkTasks(guildID, enabled)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
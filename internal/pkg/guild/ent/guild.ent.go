@@ -0,0 +1,53 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// AssignStrategy represents how a task assigned to a role is distributed among the role members.
+type AssignStrategy string
+
+const (
+	RoundRobin  AssignStrategy = "RoundRobin"
+	LeastLoaded AssignStrategy = "LeastLoaded"
+	Random      AssignStrategy = "Random"
+	Queue       AssignStrategy = "Queue"
+)
+
+// String method for the AssignStrategy type, to provide string representation of each strategy.
+func (a AssignStrategy) String() string {
+	switch a {
+	case RoundRobin:
+		return "Round-robin"
+	case LeastLoaded:
+		return "Least open tasks"
+	case Random:
+		return "Random"
+	case Queue:
+		return "Role queue"
+	default:
+		return "Unknown"
+	}
+}
+
+// Settings represents per-guild configuration for GORM
+type Settings struct {
+	gorm.Model
+	GuildID        string         `gorm:"not null;uniqueIndex" json:"guild_id"`
+	AssignStrategy AssignStrategy `gorm:"type:varchar(20);default:'RoundRobin'" json:"assign_strategy"` // How role tasks are assigned
+	Timezone       string         `gorm:"type:varchar(64);default:'UTC'" json:"timezone"`               // IANA time zone of the guild
+	DigestChannel  string         `json:"digest_channel"`                                               // Channel digests are posted in
+	DigestHour     int            `gorm:"default:9" json:"digest_hour"`                                 // Local hour digests are sent at
+	DigestWeekday  time.Weekday   `gorm:"default:1" json:"digest_weekday"`                              // Local weekday weekly digests are sent on
+	LinkTasks      bool           `gorm:"default:false" json:"link_tasks"`                              // Whether task references in messages get a preview
+}
+
+// Location returns the time zone of the guild, falling back to UTC if it is unknown
+func (s Settings) Location() *time.Location {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,69 @@
+package svc
+
+import (
+	"errors"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"taskchord/internal/pkg/guild/ent"
+	"time"
+)
+
+type GuildService struct {
+	db gossiper.Database
+}
+
+// NewGuildService initializes a new guild service
+func NewGuildService(db gossiper.Database) *GuildService {
+	return &GuildService{db: db}
+}
+
+// GetSettings returns the settings of a guild, falling back to defaults if none were saved yet
+func (s *GuildService) GetSettings(guildID string) (ent.Settings, error) {
+	var settings ent.Settings
+	err := s.db.GetDB().Where("guild_id = ?", guildID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ent.Settings{
+			GuildID:        guildID,
+			AssignStrategy: ent.RoundRobin,
+			Timezone:       "UTC",
+			DigestHour:     9,
+			DigestWeekday:  time.Monday,
+		}, nil
+	}
+	return settings, err
+}
+
+// SetAssignStrategy stores the role assignment strategy of a guild
+func (s *GuildService) SetAssignStrategy(guildID string, strategy ent.AssignStrategy) error {
+	return s.updateSettings(guildID, map[string]any{"assign_strategy": strategy})
+}
+
+// SetDigestSchedule stores where and when the digests of a guild are sent
+func (s *GuildService) SetDigestSchedule(guildID, channelID string, hour int, weekday time.Weekday) error {
+	return s.updateSettings(guildID, map[string]any{
+		"digest_channel": channelID,
+		"digest_hour":    hour,
+		"digest_weekday": weekday,
+	})
+}
+
+// SetTimezone stores the default time zone of a guild
+func (s *GuildService) SetTimezone(guildID, timezone string) error {
+	return s.updateSettings(guildID, map[string]any{"timezone": timezone})
+}
+
+// SetLinkTasks turns previews of task references in chat messages on or off
+func (s *GuildService) SetLinkTasks(guildID string, enabled bool) error {
+	return s.updateSettings(guildID, map[string]any{"link_tasks": enabled})
+}
+
+// updateSettings applies the given column values to the settings of a guild, creating the row if needed
+func (s *GuildService) updateSettings(guildID string, values map[string]any) error {
+	return s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		settings := ent.Settings{GuildID: guildID}
+		if err := tx.Where("guild_id = ?", guildID).FirstOrCreate(&settings).Error; err != nil {
+			return err
+		}
+		return tx.Model(&settings).Updates(values).Error
+	})
+}
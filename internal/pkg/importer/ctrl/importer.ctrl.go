@@ -0,0 +1,81 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"taskchord/internal/pkg/importer/ent"
+	"taskchord/internal/pkg/importer/svc"
+	taskEnt "taskchord/internal/pkg/task/ent"
+)
+
+// maxItems is the most tasks one import may bring in
+const maxItems = 1000
+
+type ImportController struct {
+	importService *svc.ImportService
+}
+
+// NewImportController creates a new import controller
+func NewImportController(importService *svc.ImportService) *ImportController {
+	return &ImportController{importService: importService}
+}
+
+// Plan reads an import file of a source and fits its tasks to the guild, which has the given roles, without storing them
+func (c *ImportController) Plan(guildID, userID, source string, data []byte, roles []string) (ent.Plan, error) {
+	items, err := ent.Parse(ent.Source(source), data)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Plan{}, err
+	}
+	if len(items) > maxItems {
+		log.Println("Controller error: Too many tasks to import")
+		return ent.Plan{}, fmt.Errorf("the file has %d tasks, but at most %d can be imported at once", len(items), maxItems)
+	}
+
+	plan, err := c.importService.Plan(guildID, userID, roles, items)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Plan{}, err
+	}
+	if len(plan.Tasks) == 0 {
+		log.Println("Controller error: No tasks to import")
+		return ent.Plan{}, fmt.Errorf("the file has no tasks to import")
+	}
+	return plan, nil
+}
+
+// Commit stores the tasks of a previewed import
+func (c *ImportController) Commit(guildID string, plan ent.Plan) ([]taskEnt.Task, error) {
+	tasks, err := c.importService.Commit(guildID, plan)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// SetMapping ties a user name of another tool to a member; an empty member removes the mapping
+func (c *ImportController) SetMapping(guildID, name, userID string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		log.Println("Controller error: User names must have between 1 and 100 characters")
+		return fmt.Errorf("user names must have between 1 and 100 characters")
+	}
+
+	if err := c.importService.SetMapping(guildID, name, userID); err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// GetMappings lists the user mappings of a guild
+func (c *ImportController) GetMappings(guildID string) ([]ent.Mapping, error) {
+	mappings, err := c.importService.GetMappings(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return mappings, nil
+}
@@ -0,0 +1,71 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	exportEnt "taskchord/internal/pkg/export/ent"
+	fieldEnt "taskchord/internal/pkg/field/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+// Source is the tool an import file comes from
+type Source string
+
+const (
+	Trello    Source = "trello"
+	Todoist   Source = "todoist"
+	GitHub    Source = "github"
+	TaskChord Source = "taskchord"
+)
+
+// Item is a task read from an import file, before it is fitted to the guild
+type Item struct {
+	Title       string
+	Description string
+	Status      string // Name of the status, list or section in the source, used when the workflow has it
+	Done        bool
+	Priority    string // Name of the priority in the source
+	Rank        int    // Position on the source's own numbered scale, 1 the most urgent, 0 if unknown
+	Ranks       int    // Length of the source's numbered scale
+	Author      string // Discord ID or user name in the source
+	Assignee    string // Discord ID or user name in the source, empty if unassigned
+	Role        string // Discord role ID of a task queued for a role, kept when the guild has the role
+	Project     string
+	Milestone   string
+	DueAt       *time.Time
+	Estimate    int // Minutes
+	Tags        []string
+	Checklist   []exportEnt.ChecklistItem
+	Comments    []Comment
+	Watchers    []string          // Discord IDs or user names in the source
+	Fields      map[string]string // Custom field values by field name, matched to the guild's fields by name
+	CreatedAt   *time.Time
+	CompletedAt *time.Time
+}
+
+// Comment is a comment read from an import file
+type Comment struct {
+	Author    string // Discord ID or user name in the source
+	Content   string
+	CreatedAt *time.Time
+}
+
+// Mapping ties a user name of another tool to a member of the guild, for GORM
+type Mapping struct {
+	gorm.Model
+	GuildID string `gorm:"not null;uniqueIndex:idx_import_mapping" json:"guild_id"`
+	Name    string `gorm:"type:varchar(100);not null;uniqueIndex:idx_import_mapping" json:"name"` // Lower case
+	UserID  string `gorm:"not null" json:"user_id"`
+}
+
+// Plan is an import fitted to a guild and ready to be stored, so it can be previewed first
+type Plan struct {
+	Tasks    []taskEnt.Task
+	Comments [][]taskEnt.Comment // Comments of each task, in the order of the tasks
+	Watchers [][]string          // Members watching each task, in the order of the tasks
+	Values   [][]fieldEnt.Value  // Custom field values of each task, in the order of the tasks
+	Done     int                 // Tasks that arrive in the terminal state
+	Skipped  int                 // Items without a title
+	Unmapped []string            // Users of the source without a mapping; the importing member stands in for them
+	Missing  []string            // Projects, milestones, roles, fields and field values the guild does not have; tasks are imported without them
+}
@@ -0,0 +1,365 @@
+package ent
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	exportEnt "taskchord/internal/pkg/export/ent"
+	"time"
+)
+
+// todoistUser matches the users of Todoist exports, such as Alice (12345)
+var todoistUser = regexp.MustCompile(`^(.*?)\s*\(\d+\)$`)
+
+// Parse reads the tasks of an import file
+func Parse(source Source, data []byte) ([]Item, error) {
+	switch source {
+	case Trello:
+		return parseTrello(data)
+	case Todoist:
+		return parseTodoist(data)
+	case GitHub:
+		return parseGitHub(data)
+	case TaskChord:
+		return parseTaskChord(data)
+	}
+	return nil, fmt.Errorf("unknown source %s", source)
+}
+
+// parseTrello reads a board exported from Trello as JSON. Cards become tasks in the status named like
+// their list, archived cards are done, labels become tags and the first member of a card its assignee.
+func parseTrello(data []byte) ([]Item, error) {
+	var board struct {
+		Cards []struct {
+			ID               string   `json:"id"`
+			Name             string   `json:"name"`
+			Desc             string   `json:"desc"`
+			Closed           bool     `json:"closed"`
+			IDList           string   `json:"idList"`
+			IDLabels         []string `json:"idLabels"`
+			IDMembers        []string `json:"idMembers"`
+			Due              string   `json:"due"`
+			DateLastActivity string   `json:"dateLastActivity"`
+		} `json:"cards"`
+		Lists []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"lists"`
+		Labels []struct {
+			ID    string `json:"id"`
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+		Members []struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+		} `json:"members"`
+		Checklists []struct {
+			IDCard     string `json:"idCard"`
+			CheckItems []struct {
+				Name  string  `json:"name"`
+				State string  `json:"state"`
+				Pos   float64 `json:"pos"`
+			} `json:"checkItems"`
+		} `json:"checklists"`
+		Actions []struct {
+			Type string `json:"type"`
+			Date string `json:"date"`
+			Data struct {
+				Text string `json:"text"`
+				Card struct {
+					ID string `json:"id"`
+				} `json:"card"`
+			} `json:"data"`
+			MemberCreator struct {
+				Username string `json:"username"`
+			} `json:"memberCreator"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, fmt.Errorf("the file is not a Trello board export: %v", err)
+	}
+
+	lists := make(map[string]string)
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+	}
+	labels := make(map[string]string)
+	for _, label := range board.Labels {
+		labels[label.ID] = label.Name
+		if label.Name == "" {
+			labels[label.ID] = label.Color
+		}
+	}
+	members := make(map[string]string)
+	for _, member := range board.Members {
+		members[member.ID] = member.Username
+	}
+
+	checklists := make(map[string][]exportEnt.ChecklistItem)
+	for _, checklist := range board.Checklists {
+		items := checklist.CheckItems
+		sort.SliceStable(items, func(a, b int) bool { return items[a].Pos < items[b].Pos })
+		for _, item := range items {
+			checklists[checklist.IDCard] = append(checklists[checklist.IDCard], exportEnt.ChecklistItem{Text: item.Name, Done: item.State == "complete"})
+		}
+	}
+
+	// Actions come newest first
+	comments := make(map[string][]Comment)
+	authors := make(map[string]string)
+	for index := len(board.Actions) - 1; index >= 0; index-- {
+		action := board.Actions[index]
+		switch action.Type {
+		case "commentCard":
+			comments[action.Data.Card.ID] = append(comments[action.Data.Card.ID], Comment{
+				Author:    action.MemberCreator.Username,
+				Content:   action.Data.Text,
+				CreatedAt: parseTime(action.Date),
+			})
+		case "createCard":
+			authors[action.Data.Card.ID] = action.MemberCreator.Username
+		}
+	}
+
+	var items []Item
+	for _, card := range board.Cards {
+		item := Item{
+			Title:       card.Name,
+			Description: card.Desc,
+			Status:      lists[card.IDList],
+			Done:        card.Closed,
+			Author:      authors[card.ID],
+			DueAt:       parseDay(card.Due),
+			Checklist:   checklists[card.ID],
+			Comments:    comments[card.ID],
+		}
+		for _, id := range card.IDLabels {
+			if name := labels[id]; name != "" {
+				item.Tags = append(item.Tags, name)
+			}
+		}
+		if len(card.IDMembers) > 0 {
+			item.Assignee = members[card.IDMembers[0]]
+		}
+		if card.Closed {
+			item.CompletedAt = parseTime(card.DateLastActivity)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// parseTodoist reads a project exported from Todoist as CSV. Tasks take the status named like their
+// section, notes become comments of the task above them, and priority 1 is the most urgent of four.
+func parseTodoist(data []byte) ([]Item, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil || len(rows) == 0 {
+		return nil, fmt.Errorf("the file is not a Todoist CSV export")
+	}
+
+	columns := make(map[string]int)
+	for index, name := range rows[0] {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = index
+	}
+	if _, found := columns["CONTENT"]; !found {
+		return nil, fmt.Errorf("the file is not a Todoist CSV export: it has no CONTENT column")
+	}
+	cell := func(row []string, name string) string {
+		if index, found := columns[name]; found && index < len(row) {
+			return strings.TrimSpace(row[index])
+		}
+		return ""
+	}
+	user := func(value string) string {
+		if match := todoistUser.FindStringSubmatch(value); match != nil {
+			return match[1]
+		}
+		return value
+	}
+
+	var items []Item
+	section := ""
+	for _, row := range rows[1:] {
+		switch strings.ToLower(cell(row, "TYPE")) {
+		case "section":
+			section = cell(row, "CONTENT")
+		case "note":
+			if len(items) > 0 {
+				last := &items[len(items)-1]
+				last.Comments = append(last.Comments, Comment{Author: user(cell(row, "AUTHOR")), Content: cell(row, "CONTENT")})
+			}
+		case "task", "":
+			item := Item{
+				Title:       cell(row, "CONTENT"),
+				Description: cell(row, "DESCRIPTION"),
+				Status:      section,
+				Author:      user(cell(row, "AUTHOR")),
+				Assignee:    user(cell(row, "RESPONSIBLE")),
+				DueAt:       parseDay(cell(row, "DATE")),
+				Ranks:       4,
+			}
+			item.Rank, _ = strconv.Atoi(cell(row, "PRIORITY"))
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// githubUser is a user as both the REST API and the gh command line tool write them
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// parseGitHub reads issues exported from GitHub as a JSON array, either from the REST API or from
+// gh issue list --json. Labels become tags, closed issues are done and pull requests are left out.
+func parseGitHub(data []byte) ([]Item, error) {
+	var issues []struct {
+		Title       string                  `json:"title"`
+		Body        string                  `json:"body"`
+		State       string                  `json:"state"`
+		User        *githubUser             `json:"user"`
+		Author      *githubUser             `json:"author"`
+		Assignees   []githubUser            `json:"assignees"`
+		Milestone   *struct{ Title string } `json:"milestone"`
+		Comments    json.RawMessage         `json:"comments"`
+		CreatedAt   string                  `json:"created_at"`
+		CreatedAtCL string                  `json:"createdAt"`
+		ClosedAt    string                  `json:"closed_at"`
+		ClosedAtCL  string                  `json:"closedAt"`
+		PullRequest json.RawMessage         `json:"pull_request"`
+		Labels      []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, fmt.Errorf("the file is not a GitHub issues export: %v", err)
+	}
+
+	var items []Item
+	for _, issue := range issues {
+		if len(issue.PullRequest) > 0 {
+			continue
+		}
+		item := Item{
+			Title:       issue.Title,
+			Description: issue.Body,
+			Done:        strings.EqualFold(issue.State, "closed"),
+			CreatedAt:   parseTime(either(issue.CreatedAt, issue.CreatedAtCL)),
+			CompletedAt: parseTime(either(issue.ClosedAt, issue.ClosedAtCL)),
+		}
+		if issue.User != nil {
+			item.Author = issue.User.Login
+		} else if issue.Author != nil {
+			item.Author = issue.Author.Login
+		}
+		if len(issue.Assignees) > 0 {
+			item.Assignee = issue.Assignees[0].Login
+		}
+		if issue.Milestone != nil {
+			item.Milestone = issue.Milestone.Title
+		}
+		for _, label := range issue.Labels {
+			item.Tags = append(item.Tags, label.Name)
+		}
+
+		// The REST API only counts comments, while gh lists them
+		var comments []struct {
+			Body        string      `json:"body"`
+			User        *githubUser `json:"user"`
+			Author      *githubUser `json:"author"`
+			CreatedAt   string      `json:"created_at"`
+			CreatedAtCL string      `json:"createdAt"`
+		}
+		if json.Unmarshal(issue.Comments, &comments) == nil {
+			for _, comment := range comments {
+				author := ""
+				if comment.User != nil {
+					author = comment.User.Login
+				} else if comment.Author != nil {
+					author = comment.Author.Login
+				}
+				item.Comments = append(item.Comments, Comment{Author: author, Content: comment.Body, CreatedAt: parseTime(either(comment.CreatedAt, comment.CreatedAtCL))})
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// parseTaskChord reads a JSON file written by /export
+func parseTaskChord(data []byte) ([]Item, error) {
+	var document exportEnt.Document
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("the file is not a TaskChord export: %v", err)
+	}
+	if document.Version < 1 || document.Version > exportEnt.Version {
+		return nil, fmt.Errorf("exports of version %d cannot be imported", document.Version)
+	}
+
+	var items []Item
+	for _, task := range document.Tasks {
+		createdAt := task.CreatedAt
+		item := Item{
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      task.Status,
+			Done:        task.CompletedAt != nil,
+			Priority:    task.Priority,
+			Author:      task.AuthorID,
+			Assignee:    task.ExecutorID,
+			Role:        task.RoleID,
+			Project:     task.Project,
+			Milestone:   task.Milestone,
+			DueAt:       task.DueAt,
+			Estimate:    task.Estimate,
+			Tags:        task.Tags,
+			Checklist:   task.Checklist,
+			Watchers:    task.Watchers,
+			Fields:      task.Fields,
+			CreatedAt:   &createdAt,
+			CompletedAt: task.CompletedAt,
+		}
+		for _, comment := range task.Comments {
+			commentedAt := comment.CreatedAt
+			item.Comments = append(item.Comments, Comment{Author: comment.AuthorID, Content: comment.Content, CreatedAt: &commentedAt})
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// either picks whichever of two spellings of a field an export used
+func either(snake, camel string) string {
+	if snake != "" {
+		return snake
+	}
+	return camel
+}
+
+// parseTime reads a timestamp of an export, nil if it has none
+func parseTime(value string) *time.Time {
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &at
+}
+
+// parseDay reads a due date of an export into midnight UTC of its day, the way due dates are stored
+func parseDay(value string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if at, err := time.Parse(layout, value); err == nil {
+			day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+			return &day
+		}
+	}
+	return nil
+}
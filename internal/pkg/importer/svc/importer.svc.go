@@ -0,0 +1,301 @@
+package svc
+
+import (
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	fieldEnt "taskchord/internal/pkg/field/ent"
+	"taskchord/internal/pkg/importer/ent"
+	milestoneEnt "taskchord/internal/pkg/milestone/ent"
+	prioritySvc "taskchord/internal/pkg/priority/svc"
+	projectEnt "taskchord/internal/pkg/project/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	taskSvc "taskchord/internal/pkg/task/svc"
+	workflowSvc "taskchord/internal/pkg/workflow/svc"
+	"time"
+	"unicode/utf8"
+)
+
+// discordUser matches Discord user IDs and mentions, which need no mapping
+var discordUser = regexp.MustCompile(`^<?@?!?(\d{17,20})>?$`)
+
+type ImportService struct {
+	db              gossiper.Database
+	taskService     *taskSvc.TaskService
+	workflowService *workflowSvc.WorkflowService
+	priorityService *prioritySvc.PriorityService
+}
+
+// NewImportService initializes a new import service
+func NewImportService(db gossiper.Database, taskService *taskSvc.TaskService, workflowService *workflowSvc.WorkflowService, priorityService *prioritySvc.PriorityService) *ImportService {
+	return &ImportService{db: db, taskService: taskService, workflowService: workflowService, priorityService: priorityService}
+}
+
+// SetMapping ties a user name of another tool to a member, replacing an earlier mapping of the name.
+// An empty member removes the mapping.
+func (s *ImportService) SetMapping(guildID, name, userID string) error {
+	name = strings.ToLower(name)
+	err := s.db.GetDB().Unscoped().Where("guild_id = ? AND name = ?", guildID, name).Delete(&ent.Mapping{}).Error
+	if err != nil || userID == "" {
+		return err
+	}
+	return s.db.GetDB().Create(&ent.Mapping{GuildID: guildID, Name: name, UserID: userID}).Error
+}
+
+// GetMappings lists the user mappings of a guild by name
+func (s *ImportService) GetMappings(guildID string) ([]ent.Mapping, error) {
+	var mappings []ent.Mapping
+	err := s.db.GetDB().Where("guild_id = ?", guildID).Order("name").Find(&mappings).Error
+	return mappings, err
+}
+
+// Plan fits the items of an import file to a guild: statuses to its workflow, priorities to its scale,
+// users to its members, and projects, milestones, custom fields and roles to the ones it has, given its
+// role IDs. Nothing is stored yet. Authors and executors without a mapping are replaced by the importing
+// member; watchers without one are left out.
+func (s *ImportService) Plan(guildID, importerID string, roles []string, items []ent.Item) (ent.Plan, error) {
+	var plan ent.Plan
+
+	workflow, err := s.workflowService.GetWorkflow(guildID)
+	if err != nil {
+		return plan, err
+	}
+	scale, err := s.priorityService.GetScale(guildID)
+	if err != nil {
+		return plan, err
+	}
+	mappings, err := s.GetMappings(guildID)
+	if err != nil {
+		return plan, err
+	}
+	users := make(map[string]string)
+	for _, mapping := range mappings {
+		users[mapping.Name] = mapping.UserID
+	}
+
+	var projects []projectEnt.Project
+	if err := s.db.GetDB().Where("guild_id = ?", guildID).Find(&projects).Error; err != nil {
+		return plan, err
+	}
+	var milestones []milestoneEnt.Milestone
+	if err := s.db.GetDB().Where("guild_id = ?", guildID).Find(&milestones).Error; err != nil {
+		return plan, err
+	}
+
+	var definitions []fieldEnt.Definition
+	if err := s.db.GetDB().Where("guild_id = ?", guildID).Find(&definitions).Error; err != nil {
+		return plan, err
+	}
+	fields := make(map[string]fieldEnt.Definition)
+	for _, definition := range definitions {
+		fields[strings.ToLower(definition.Name)] = definition
+	}
+
+	// findUser looks a user up by Discord ID, mention or mapping
+	findUser := func(name string) (string, bool) {
+		name = strings.TrimSpace(name)
+		if match := discordUser.FindStringSubmatch(name); match != nil {
+			return match[1], true
+		}
+		userID, found := users[strings.ToLower(name)]
+		return userID, found
+	}
+	resolveUser := func(name string) string {
+		if userID, found := findUser(name); found {
+			return userID
+		}
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(plan.Unmapped, name) {
+			plan.Unmapped = append(plan.Unmapped, name)
+		}
+		return importerID
+	}
+	missing := func(name string) {
+		if !slices.Contains(plan.Missing, name) {
+			plan.Missing = append(plan.Missing, name)
+		}
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		title := truncate(strings.TrimSpace(item.Title), 200)
+		if title == "" {
+			plan.Skipped++
+			continue
+		}
+
+		task := taskEnt.Task{
+			UserID:      resolveUser(item.Author),
+			Title:       title,
+			Description: item.Description,
+			DueAt:       item.DueAt,
+			Estimate:    max(item.Estimate, 0),
+			Tags:        []taskEnt.Tag{},
+		}
+		// A task queued for a role of the guild stays in the queue until someone claims it
+		if item.Role != "" {
+			if slices.Contains(roles, item.Role) {
+				task.RoleID = item.Role
+			} else {
+				missing("role " + item.Role)
+			}
+		}
+		task.ExecutorID = task.UserID
+		if strings.TrimSpace(item.Assignee) != "" {
+			task.ExecutorID = resolveUser(item.Assignee)
+		} else if task.RoleID != "" {
+			task.ExecutorID = ""
+		}
+		if item.CreatedAt != nil {
+			task.CreatedAt = *item.CreatedAt
+		}
+
+		// A status the workflow knows is kept unless it contradicts a closed item, such as an
+		// archived Trello card in a list named like an open state; otherwise the task starts over.
+		// Closed items are done like /bulk closes tasks, even in a workflow without a terminal state.
+		state, known := workflow.Find(item.Status)
+		if !known {
+			state = workflow.Initial()
+		}
+		if item.Done && !state.Terminal {
+			state = workflow.Terminal()
+		}
+		task.Status = taskEnt.Status(state.Name)
+		if item.Done || state.Terminal {
+			completedAt := now
+			if item.CompletedAt != nil {
+				completedAt = *item.CompletedAt
+			}
+			task.CompletedAt = &completedAt
+			plan.Done++
+		}
+
+		// Priorities match by name, by a tag named like a level, or by position on the source's scale
+		task.Priority = taskEnt.Priority(scale.Default().Name)
+		if level, found := scale.Find(item.Priority); found {
+			task.Priority = taskEnt.Priority(level.Name)
+		} else if item.Rank > 0 && item.Ranks > 0 && len(scale) > 0 {
+			position := min((item.Rank-1)*len(scale)/item.Ranks, len(scale)-1)
+			task.Priority = taskEnt.Priority(scale[position].Name)
+		} else {
+			for _, tag := range item.Tags {
+				name := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(tag), "priority:"), "priority/"))
+				if level, found := scale.Find(name); found {
+					task.Priority = taskEnt.Priority(level.Name)
+					break
+				}
+			}
+		}
+
+		if item.Project != "" {
+			index := slices.IndexFunc(projects, func(project projectEnt.Project) bool {
+				return strings.EqualFold(project.Name, item.Project) || (project.Key != "" && strings.EqualFold(project.Key, item.Project))
+			})
+			if index >= 0 {
+				task.ProjectID = &projects[index].ID
+			} else {
+				missing("project " + item.Project)
+			}
+		}
+		if item.Milestone != "" {
+			index := slices.IndexFunc(milestones, func(milestone milestoneEnt.Milestone) bool {
+				return strings.EqualFold(milestone.Name, item.Milestone)
+			})
+			if index >= 0 {
+				task.MilestoneID = &milestones[index].ID
+			} else {
+				missing("milestone " + item.Milestone)
+			}
+		}
+
+		for _, name := range normalizeTags(item.Tags) {
+			task.Tags = append(task.Tags, taskEnt.Tag{Name: name})
+		}
+		for _, checklistItem := range item.Checklist {
+			text := truncate(strings.TrimSpace(checklistItem.Text), 200)
+			if text == "" || len(task.Checklist) == 25 {
+				continue
+			}
+			task.Checklist = append(task.Checklist, taskEnt.ChecklistItem{Position: len(task.Checklist), Text: text, Done: checklistItem.Done})
+		}
+
+		var comments []taskEnt.Comment
+		for _, comment := range item.Comments {
+			content := strings.TrimSpace(comment.Content)
+			if content == "" {
+				continue
+			}
+			imported := taskEnt.Comment{UserID: resolveUser(comment.Author), Content: content}
+			if comment.CreatedAt != nil {
+				imported.CreatedAt = *comment.CreatedAt
+			}
+			comments = append(comments, imported)
+		}
+
+		// The author and executor hear about the task anyway, so they need not watch it
+		var watchers []string
+		for _, name := range item.Watchers {
+			userID, found := findUser(name)
+			if found && userID != task.UserID && userID != task.ExecutorID && !slices.Contains(watchers, userID) {
+				watchers = append(watchers, userID)
+			}
+		}
+
+		var values []fieldEnt.Value
+		for name, value := range item.Fields {
+			definition, found := fields[strings.ToLower(strings.TrimSpace(name))]
+			if !found {
+				missing("field " + name)
+				continue
+			}
+			value, err := definition.Normalize(strings.TrimSpace(value))
+			if err != nil {
+				missing(fmt.Sprintf("value %s of field %s", truncate(item.Fields[name], 50), definition.Name))
+				continue
+			}
+			if value != "" {
+				values = append(values, fieldEnt.Value{DefinitionID: definition.ID, Value: value})
+			}
+		}
+
+		plan.Tasks = append(plan.Tasks, task)
+		plan.Comments = append(plan.Comments, comments)
+		plan.Watchers = append(plan.Watchers, watchers)
+		plan.Values = append(plan.Values, values)
+	}
+
+	sort.Strings(plan.Unmapped)
+	sort.Strings(plan.Missing)
+	return plan, nil
+}
+
+// Commit stores the tasks of a plan in one transaction
+func (s *ImportService) Commit(guildID string, plan ent.Plan) ([]taskEnt.Task, error) {
+	return s.taskService.ImportTasks(guildID, plan.Tasks, plan.Comments, plan.Watchers, plan.Values)
+}
+
+// normalizeTags brings tags into the form /create stores them in, keeping the first ten
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = truncate(strings.Join(strings.Fields(strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))), "-"), 30)
+		if tag != "" && !slices.Contains(normalized, tag) && len(normalized) < 10 {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// truncate shortens text to at most limit bytes without splitting a character
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit]
+}
@@ -0,0 +1,109 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"taskchord/internal/pkg/milestone/ent"
+	"taskchord/internal/pkg/milestone/svc"
+	"time"
+)
+
+type MilestoneController struct {
+	milestoneService *svc.MilestoneService
+}
+
+// NewMilestoneController creates a new milestone controller
+func NewMilestoneController(milestoneService *svc.MilestoneService) *MilestoneController {
+	return &MilestoneController{milestoneService: milestoneService}
+}
+
+// CreateMilestone validates and stores a new milestone running from the start to the end date
+func (c *MilestoneController) CreateMilestone(guildID, name string, startDate, endDate time.Time) (ent.Milestone, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		log.Println("Controller error: Milestone name must have between 1 and 100 characters")
+		return ent.Milestone{}, fmt.Errorf("milestone name must have between 1 and 100 characters")
+	}
+	if strings.EqualFold(name, "none") {
+		log.Println("Controller error: Milestone name none is reserved")
+		return ent.Milestone{}, fmt.Errorf("milestone name none is reserved")
+	}
+	if endDate.Before(startDate) {
+		log.Println("Controller error: Milestone must end after it starts")
+		return ent.Milestone{}, fmt.Errorf("milestone must end after it starts")
+	}
+
+	milestone, err := c.milestoneService.CreateMilestone(guildID, name, startDate, endDate)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Milestone{}, err
+	}
+	return milestone, nil
+}
+
+// CloseMilestone closes an open milestone
+func (c *MilestoneController) CloseMilestone(guildID, name string) (ent.Milestone, error) {
+	milestone, err := c.milestoneService.CloseMilestone(guildID, name)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Milestone{}, err
+	}
+	return milestone, nil
+}
+
+// GetMilestone finds a milestone by name, open or closed
+func (c *MilestoneController) GetMilestone(guildID, name string) (ent.Milestone, error) {
+	milestone, err := c.milestoneService.GetMilestone(guildID, name)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Milestone{}, err
+	}
+	return milestone, nil
+}
+
+// GetOpenMilestone finds a milestone that tasks can still be added to
+func (c *MilestoneController) GetOpenMilestone(guildID, name string) (ent.Milestone, error) {
+	milestone, err := c.GetMilestone(guildID, name)
+	if err != nil {
+		return ent.Milestone{}, err
+	}
+	if milestone.ClosedAt != nil {
+		log.Println("Controller error: Milestone is closed")
+		return ent.Milestone{}, fmt.Errorf("milestone %s is closed", milestone.Name)
+	}
+	return milestone, nil
+}
+
+// GetMilestones lists the milestones of a guild, the latest first
+func (c *MilestoneController) GetMilestones(guildID string, includeClosed bool) ([]ent.Milestone, error) {
+	milestones, err := c.milestoneService.GetMilestones(guildID, includeClosed)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return milestones, nil
+}
+
+// GetProgress reports the progress of a milestone, or of the current one when no name is given.
+// Today is the current date at midnight UTC, the same way due dates are stored.
+func (c *MilestoneController) GetProgress(guildID, name string, today time.Time) (ent.Progress, error) {
+	var milestone ent.Milestone
+	var err error
+	if name == "" {
+		milestone, err = c.milestoneService.GetCurrentMilestone(guildID, today)
+	} else {
+		milestone, err = c.milestoneService.GetMilestone(guildID, name)
+	}
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Progress{}, err
+	}
+
+	progress, err := c.milestoneService.GetProgress(milestone, today)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Progress{}, err
+	}
+	return progress, nil
+}
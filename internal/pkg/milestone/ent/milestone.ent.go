@@ -0,0 +1,26 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+// Milestone represents a sprint or release that tasks can belong to, for GORM
+type Milestone struct {
+	gorm.Model
+	GuildID   string     `gorm:"not null;uniqueIndex:idx_milestone_name" json:"guild_id"`
+	Name      string     `gorm:"not null;uniqueIndex:idx_milestone_name" json:"name"`
+	StartDate time.Time  `gorm:"not null" json:"start_date"` // Midnight UTC of the first day, like due dates
+	EndDate   time.Time  `gorm:"not null" json:"end_date"`   // Midnight UTC of the last day
+	ClosedAt  *time.Time `json:"closed_at"`                  // Nil while the milestone is open
+}
+
+// Progress holds how far the tasks of a milestone are
+type Progress struct {
+	Milestone     Milestone
+	Total         int64
+	Done          int64
+	DaysRemaining int            // Days left including today, zero once the milestone ended
+	AtRisk        []taskEnt.Task // Open tasks that are overdue, due after the end, or still unclaimed
+}
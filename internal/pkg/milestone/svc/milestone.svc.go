@@ -0,0 +1,112 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"taskchord/internal/pkg/milestone/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+type MilestoneService struct {
+	db gossiper.Database
+}
+
+// NewMilestoneService initializes a new milestone service
+func NewMilestoneService(db gossiper.Database) *MilestoneService {
+	return &MilestoneService{db: db}
+}
+
+// CreateMilestone adds a milestone to a guild
+func (s *MilestoneService) CreateMilestone(guildID, name string, startDate, endDate time.Time) (ent.Milestone, error) {
+	if _, err := s.GetMilestone(guildID, name); err == nil {
+		return ent.Milestone{}, fmt.Errorf("milestone %s already exists", name)
+	}
+
+	milestone := ent.Milestone{GuildID: guildID, Name: name, StartDate: startDate, EndDate: endDate}
+	err := s.db.GetDB().Create(&milestone).Error
+	return milestone, err
+}
+
+// CloseMilestone marks an open milestone as closed; its tasks keep their milestone
+func (s *MilestoneService) CloseMilestone(guildID, name string) (ent.Milestone, error) {
+	milestone, err := s.GetMilestone(guildID, name)
+	if err != nil {
+		return milestone, err
+	}
+	if milestone.ClosedAt != nil {
+		return milestone, fmt.Errorf("milestone %s is already closed", milestone.Name)
+	}
+
+	now := time.Now()
+	milestone.ClosedAt = &now
+	err = s.db.GetDB().Model(&milestone).Update("closed_at", now).Error
+	return milestone, err
+}
+
+// GetMilestone finds a milestone of a guild by name, ignoring case
+func (s *MilestoneService) GetMilestone(guildID, name string) (ent.Milestone, error) {
+	var milestone ent.Milestone
+	err := s.db.GetDB().Where("guild_id = ? AND LOWER(name) = LOWER(?)", guildID, name).First(&milestone).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return milestone, fmt.Errorf("milestone %s does not exist", name)
+	}
+	return milestone, err
+}
+
+// GetMilestones lists the milestones of a guild, the latest first
+func (s *MilestoneService) GetMilestones(guildID string, includeClosed bool) ([]ent.Milestone, error) {
+	var milestones []ent.Milestone
+	query := s.db.GetDB().Where("guild_id = ?", guildID)
+	if !includeClosed {
+		query = query.Where("closed_at IS NULL")
+	}
+	err := query.Order("start_date DESC").Find(&milestones).Error
+	return milestones, err
+}
+
+// GetCurrentMilestone finds the open milestone running today, or else the open one that starts last
+func (s *MilestoneService) GetCurrentMilestone(guildID string, today time.Time) (ent.Milestone, error) {
+	var milestone ent.Milestone
+	err := s.db.GetDB().
+		Where("guild_id = ? AND closed_at IS NULL AND start_date <= ? AND end_date >= ?", guildID, today, today).
+		Order("start_date DESC").
+		First(&milestone).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = s.db.GetDB().
+			Where("guild_id = ? AND closed_at IS NULL", guildID).
+			Order("start_date DESC").
+			First(&milestone).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return milestone, fmt.Errorf("there is no open milestone")
+	}
+	return milestone, err
+}
+
+// GetProgress reports how far the tasks of a milestone are as of today
+func (s *MilestoneService) GetProgress(milestone ent.Milestone, today time.Time) (ent.Progress, error) {
+	progress := ent.Progress{Milestone: milestone}
+	db := s.db.GetDB()
+
+	err := db.Model(&taskEnt.Task{}).Where("milestone_id = ?", milestone.ID).Count(&progress.Total).Error
+	if err != nil {
+		return progress, err
+	}
+	err = db.Model(&taskEnt.Task{}).Where("milestone_id = ? AND completed_at IS NOT NULL", milestone.ID).Count(&progress.Done).Error
+	if err != nil {
+		return progress, err
+	}
+
+	if !today.After(milestone.EndDate) {
+		progress.DaysRemaining = int(milestone.EndDate.Sub(today).Hours()/24) + 1
+	}
+
+	err = db.Where("milestone_id = ? AND completed_at IS NULL", milestone.ID).
+		Where("due_at < ? OR due_at > ? OR executor_id = ''", today, milestone.EndDate).
+		Order("task_id_in_guild").
+		Find(&progress.AtRisk).Error
+	return progress, err
+}
@@ -0,0 +1,79 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"taskchord/internal/pkg/notify/ent"
+	"taskchord/internal/pkg/notify/svc"
+)
+
+type NotifyController struct {
+	notifyService *svc.NotifyService
+}
+
+// NewNotifyController creates a new notification controller
+func NewNotifyController(notifyService *svc.NotifyService) *NotifyController {
+	return &NotifyController{notifyService: notifyService}
+}
+
+// GetPreferences retrieves the delivery of every event for a user
+func (c *NotifyController) GetPreferences(userID string) (map[ent.Event]ent.Delivery, error) {
+	return c.notifyService.GetPreferences(userID)
+}
+
+// SetPreference validates and stores how a user wants to be notified about an event
+func (c *NotifyController) SetPreference(userID, event, delivery string) error {
+	validEvents := map[string]bool{string(ent.Assigned): true, string(ent.Reassigned): true, string(ent.Commented): true}
+	if !validEvents[event] {
+		log.Println("Controller error: Invalid notification event")
+		return fmt.Errorf("invalid notification event")
+	}
+
+	validDeliveries := map[string]bool{
+		string(ent.Mention):    true,
+		string(ent.DM):         true,
+		string(ent.DigestOnly): true,
+		string(ent.Mute):       true,
+	}
+	if !validDeliveries[delivery] {
+		log.Println("Controller error: Invalid notification delivery")
+		return fmt.Errorf("invalid notification delivery")
+	}
+
+	err := c.notifyService.SetPreference(userID, ent.Event(event), ent.Delivery(delivery))
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// GetQuietHours retrieves the quiet window of a user, or nil if none is set
+func (c *NotifyController) GetQuietHours(userID string) (*ent.QuietHours, error) {
+	return c.notifyService.GetQuietHours(userID)
+}
+
+// SetQuietHours validates and stores the quiet window of a user
+func (c *NotifyController) SetQuietHours(userID string, start, end int) error {
+	if start < 0 || start > 23 || end < 0 || end > 23 {
+		log.Println("Controller error: Quiet hours must be between 0 and 23")
+		return fmt.Errorf("quiet hours must be between 0 and 23")
+	}
+
+	err := c.notifyService.SetQuietHours(userID, start, end)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// AddPending holds a notification back for the user's next digest
+func (c *NotifyController) AddPending(guildID, userID string, event ent.Event, content string) error {
+	return c.notifyService.AddPending(guildID, userID, event, content)
+}
+
+// CountPending returns how many notifications are waiting for the user's next digest
+func (c *NotifyController) CountPending(userID string) (int64, error) {
+	return c.notifyService.CountPending(userID)
+}
@@ -0,0 +1,81 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Event represents the kinds of notifications a user can receive.
+type Event string
+
+const (
+	Assigned   Event = "Assigned"
+	Reassigned Event = "Reassigned"
+	Commented  Event = "Commented"
+)
+
+// Events lists every event a user can configure, in display order.
+var Events = []Event{Assigned, Reassigned, Commented}
+
+// Delivery represents how a notification reaches a user.
+type Delivery string
+
+const (
+	Mention    Delivery = "Mention"
+	DM         Delivery = "DM"
+	DigestOnly Delivery = "Digest"
+	Mute       Delivery = "Mute"
+)
+
+// String method for the Delivery type, to provide string representation of each delivery.
+func (d Delivery) String() string {
+	switch d {
+	case Mention:
+		return "Channel mention"
+	case DM:
+		return "Direct message"
+	case DigestOnly:
+		return "Digest only"
+	case Mute:
+		return "Muted"
+	default:
+		return "Unknown"
+	}
+}
+
+// Preference represents how a user wants to be notified about one event
+type Preference struct {
+	gorm.Model
+	UserID   string   `gorm:"not null;uniqueIndex:idx_preference" json:"user_id"`
+	Event    Event    `gorm:"type:varchar(20);not null;uniqueIndex:idx_preference" json:"event"`
+	Delivery Delivery `gorm:"type:varchar(20);not null" json:"delivery"`
+}
+
+// QuietHours represents the daily window in which a user only receives notifications in digests
+type QuietHours struct {
+	gorm.Model
+	UserID    string `gorm:"not null;uniqueIndex" json:"user_id"`
+	StartHour int    `gorm:"not null" json:"start_hour"` // Hour of the day the window starts, inclusive
+	EndHour   int    `gorm:"not null" json:"end_hour"`   // Hour of the day the window ends, exclusive
+}
+
+// Contains reports whether the given hour of the day falls inside the quiet window
+func (q QuietHours) Contains(hour int) bool {
+	if q.StartHour == q.EndHour {
+		return false
+	}
+	if q.StartHour < q.EndHour {
+		return hour >= q.StartHour && hour < q.EndHour
+	}
+	return hour >= q.StartHour || hour < q.EndHour // The window wraps around midnight
+}
+
+// Pending represents a notification held back for the user's next digest
+type Pending struct {
+	gorm.Model
+	GuildID     string     `gorm:"not null;index:idx_pending" json:"guild_id"`
+	UserID      string     `gorm:"not null;index:idx_pending" json:"user_id"`
+	Event       Event      `gorm:"type:varchar(20);not null" json:"event"`
+	Content     string     `gorm:"type:text" json:"content"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+}
@@ -0,0 +1,89 @@
+package svc
+
+import (
+	"errors"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"taskchord/internal/pkg/notify/ent"
+)
+
+type NotifyService struct {
+	db gossiper.Database
+}
+
+// NewNotifyService initializes a new notification service
+func NewNotifyService(db gossiper.Database) *NotifyService {
+	return &NotifyService{db: db}
+}
+
+// GetPreferences returns the delivery of every event for a user, defaulting to channel mentions
+func (s *NotifyService) GetPreferences(userID string) (map[ent.Event]ent.Delivery, error) {
+	var preferences []ent.Preference
+	err := s.db.GetDB().Where("user_id = ?", userID).Find(&preferences).Error
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make(map[ent.Event]ent.Delivery, len(ent.Events))
+	for _, event := range ent.Events {
+		deliveries[event] = ent.Mention
+	}
+	for _, preference := range preferences {
+		deliveries[preference.Event] = preference.Delivery
+	}
+	return deliveries, nil
+}
+
+// SetPreference stores how a user wants to be notified about an event
+func (s *NotifyService) SetPreference(userID string, event ent.Event, delivery ent.Delivery) error {
+	preference := ent.Preference{UserID: userID, Event: event}
+	return s.db.GetDB().
+		Where("user_id = ? AND event = ?", userID, event).
+		Assign(ent.Preference{Delivery: delivery}).
+		FirstOrCreate(&preference).Error
+}
+
+// GetQuietHours returns the quiet window of a user, or nil if none is set
+func (s *NotifyService) GetQuietHours(userID string) (*ent.QuietHours, error) {
+	var quiet ent.QuietHours
+	err := s.db.GetDB().Where("user_id = ?", userID).First(&quiet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &quiet, nil
+}
+
+// SetQuietHours stores the quiet window of a user; an empty window removes it
+func (s *NotifyService) SetQuietHours(userID string, start, end int) error {
+	if start == end {
+		return s.db.GetDB().Unscoped().Where("user_id = ?", userID).Delete(&ent.QuietHours{}).Error
+	}
+
+	quiet := ent.QuietHours{UserID: userID}
+	return s.db.GetDB().
+		Where("user_id = ?", userID).
+		Assign(map[string]any{"start_hour": start, "end_hour": end}).
+		FirstOrCreate(&quiet).Error
+}
+
+// AddPending holds a notification back for the user's next digest
+func (s *NotifyService) AddPending(guildID, userID string, event ent.Event, content string) error {
+	return s.db.GetDB().Create(&ent.Pending{
+		GuildID: guildID,
+		UserID:  userID,
+		Event:   event,
+		Content: content,
+	}).Error
+}
+
+// CountPending returns how many notifications are waiting for the user's next digest
+func (s *NotifyService) CountPending(userID string) (int64, error) {
+	var count int64
+	err := s.db.GetDB().Model(&ent.Pending{}).
+		Where("user_id = ? AND delivered_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
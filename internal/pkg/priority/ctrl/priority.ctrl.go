@@ -0,0 +1,115 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"taskchord/internal/pkg/priority/ent"
+	"taskchord/internal/pkg/priority/svc"
+)
+
+// maxLevels keeps every level selectable, as autocomplete offers at most 25 choices
+const maxLevels = 25
+
+type PriorityController struct {
+	priorityService *svc.PriorityService
+}
+
+// NewPriorityController creates a new priority controller
+func NewPriorityController(priorityService *svc.PriorityService) *PriorityController {
+	return &PriorityController{priorityService: priorityService}
+}
+
+// GetScale retrieves the priority scale of a guild
+func (c *PriorityController) GetScale(guildID string) (ent.Scale, error) {
+	scale, err := c.priorityService.GetScale(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return scale, nil
+}
+
+// SetScale validates a comma separated list of level names, the most urgent first, and stores it
+func (c *PriorityController) SetScale(guildID, levels string) (ent.Scale, error) {
+	var names []string
+	for _, name := range strings.Split(levels, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) > 20 {
+			log.Println("Controller error: Priority names must have between 1 and 20 characters")
+			return nil, fmt.Errorf("priority names must have between 1 and 20 characters")
+		}
+		for _, other := range names {
+			if strings.EqualFold(other, name) {
+				log.Println("Controller error: Priority names must be unique")
+				return nil, fmt.Errorf("priority %s is listed twice", name)
+			}
+		}
+		names = append(names, name)
+	}
+	if len(names) < 2 || len(names) > maxLevels {
+		log.Println("Controller error: A priority scale must have between 2 and 25 levels")
+		return nil, fmt.Errorf("a priority scale must have between 2 and %d levels", maxLevels)
+	}
+
+	scale, err := c.priorityService.SetScale(guildID, names)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return scale, nil
+}
+
+// StyleLevel sets the color, written as a hex code such as #FF0000, and the emoji of a level.
+// Empty values are left as they are, and none clears them.
+func (c *PriorityController) StyleLevel(guildID, name, color, emoji string) (ent.Level, error) {
+	var colorValue *int
+	if color != "" {
+		value := 0
+		if !strings.EqualFold(color, "none") {
+			parsed, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+			if err != nil || parsed > 0xFFFFFF {
+				log.Println("Controller error: Invalid color")
+				return ent.Level{}, fmt.Errorf("color must be a hex code such as #FF0000")
+			}
+			value = int(parsed)
+		}
+		colorValue = &value
+	}
+
+	var emojiValue *string
+	if emoji != "" {
+		value := strings.TrimSpace(emoji)
+		if strings.EqualFold(value, "none") {
+			value = ""
+		}
+		if len(value) > 64 {
+			log.Println("Controller error: Emoji is too long")
+			return ent.Level{}, fmt.Errorf("emoji must be a single emoji")
+		}
+		emojiValue = &value
+	}
+
+	if colorValue == nil && emojiValue == nil {
+		log.Println("Controller error: Color or emoji must be provided")
+		return ent.Level{}, fmt.Errorf("color or emoji must be provided")
+	}
+
+	level, err := c.priorityService.StyleLevel(guildID, name, colorValue, emojiValue)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Level{}, err
+	}
+	return level, nil
+}
+
+// ResetScale goes back to the default High, Medium and Low scale
+func (c *PriorityController) ResetScale(guildID string) error {
+	err := c.priorityService.ResetScale(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	"strings"
+)
+
+// Level is one step of a guild's priority scale, for GORM
+type Level struct {
+	gorm.Model
+	GuildID  string `gorm:"not null;uniqueIndex:idx_priority_name" json:"guild_id"`
+	Name     string `gorm:"type:varchar(20);not null;uniqueIndex:idx_priority_name" json:"name"`
+	Position int    `gorm:"not null" json:"position"` // 0 is the most urgent level
+	Color    int    `json:"color"`                    // Embed color of tasks with this priority, 0 for none
+	Emoji    string `json:"emoji"`                    // Shown in front of the name, optional
+}
+
+// Label renders the level with its emoji
+func (l Level) Label() string {
+	if l.Emoji == "" {
+		return l.Name
+	}
+	return l.Emoji + " " + l.Name
+}
+
+// Scale lists the priority levels of a guild, the most urgent first
+type Scale []Level
+
+// DefaultScale is used by guilds that did not define their own
+var DefaultScale = Scale{
+	{Name: "High", Position: 0, Color: 0xFF0000},
+	{Name: "Medium", Position: 1, Color: 0xFFA500},
+	{Name: "Low", Position: 2, Color: 0x00FF00},
+}
+
+// Find looks up a level by name, ignoring case
+func (s Scale) Find(name string) (Level, bool) {
+	for _, level := range s {
+		if strings.EqualFold(level.Name, name) {
+			return level, true
+		}
+	}
+	return Level{}, false
+}
+
+// Default is the level given to tasks created without a priority, the middle of the scale
+func (s Scale) Default() Level {
+	if len(s) == 0 {
+		return Level{}
+	}
+	return s[(len(s)-1)/2]
+}
+
+// Rank orders priorities by their position on the scale; unknown ones come last
+func (s Scale) Rank(name string) int {
+	if level, ok := s.Find(name); ok {
+		return level.Position
+	}
+	return len(s)
+}
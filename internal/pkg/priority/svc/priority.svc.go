@@ -0,0 +1,105 @@
+package svc
+
+import (
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"taskchord/internal/pkg/priority/ent"
+)
+
+type PriorityService struct {
+	db gossiper.Database
+}
+
+// NewPriorityService initializes a new priority service
+func NewPriorityService(db gossiper.Database) *PriorityService {
+	return &PriorityService{db: db}
+}
+
+// GetScale retrieves the priority scale of a guild, or the default scale when it has none
+func (s *PriorityService) GetScale(guildID string) (ent.Scale, error) {
+	return getScale(s.db.GetDB(), guildID)
+}
+
+// SetScale replaces the priority scale of a guild with the given names, the most urgent first.
+// Levels that keep their name also keep their color and emoji.
+func (s *PriorityService) SetScale(guildID string, names []string) (ent.Scale, error) {
+	var scale ent.Scale
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		current, err := getScale(tx, guildID)
+		if err != nil {
+			return err
+		}
+
+		scale = make(ent.Scale, 0, len(names))
+		for _, name := range names {
+			level, _ := current.Find(name)
+			scale = append(scale, ent.Level{Name: name, Color: level.Color, Emoji: level.Emoji})
+		}
+		return saveScale(tx, guildID, scale)
+	})
+	return scale, err
+}
+
+// StyleLevel changes the color and emoji of one level; nil values are left as they are
+func (s *PriorityService) StyleLevel(guildID, name string, color *int, emoji *string) (ent.Level, error) {
+	var level ent.Level
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		scale, err := getScale(tx, guildID)
+		if err != nil {
+			return err
+		}
+
+		found, ok := scale.Find(name)
+		if !ok {
+			return fmt.Errorf("priority %s does not exist", name)
+		}
+		if color != nil {
+			found.Color = *color
+		}
+		if emoji != nil {
+			found.Emoji = *emoji
+		}
+		scale[found.Position] = found
+
+		// Guilds on the default scale get it stored so the style sticks
+		if err := saveScale(tx, guildID, scale); err != nil {
+			return err
+		}
+		level = scale[found.Position]
+		return nil
+	})
+	return level, err
+}
+
+// ResetScale goes back to the default scale
+func (s *PriorityService) ResetScale(guildID string) error {
+	return s.db.GetDB().Unscoped().Where("guild_id = ?", guildID).Delete(&ent.Level{}).Error
+}
+
+// getScale reads the stored levels of a guild in order
+func getScale(db *gorm.DB, guildID string) (ent.Scale, error) {
+	var scale ent.Scale
+	err := db.Where("guild_id = ?", guildID).Order("position").Find(&scale).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(scale) == 0 {
+		return append(ent.Scale(nil), ent.DefaultScale...), nil
+	}
+	return scale, nil
+}
+
+// saveScale stores a whole scale, numbering its levels in order
+func saveScale(tx *gorm.DB, guildID string, scale ent.Scale) error {
+	if err := tx.Unscoped().Where("guild_id = ?", guildID).Delete(&ent.Level{}).Error; err != nil {
+		return err
+	}
+
+	for position := range scale {
+		scale[position].Model = gorm.Model{}
+		scale[position].GuildID = guildID
+		scale[position].Position = position
+	}
+	return tx.Create(&scale).Error
+}
@@ -0,0 +1,113 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"taskchord/internal/pkg/project/ent"
+	"taskchord/internal/pkg/project/svc"
+)
+
+// keyPattern matches project keys: a letter followed by up to nine letters or digits
+var keyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{0,9}$`)
+
+type ProjectController struct {
+	projectService *svc.ProjectService
+}
+
+// NewProjectController creates a new project controller
+func NewProjectController(projectService *svc.ProjectService) *ProjectController {
+	return &ProjectController{projectService: projectService}
+}
+
+// CreateProject validates and stores a new project
+func (c *ProjectController) CreateProject(guildID, name string) (ent.Project, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		log.Println("Controller error: Project name must have between 1 and 100 characters")
+		return ent.Project{}, fmt.Errorf("project name must have between 1 and 100 characters")
+	}
+
+	project, err := c.projectService.CreateProject(guildID, name)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Project{}, err
+	}
+	return project, nil
+}
+
+// SetKey validates and sets the key prefix of the named project, returning the updated project
+func (c *ProjectController) SetKey(guildID, name, key string) (ent.Project, error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if !keyPattern.MatchString(key) {
+		log.Println("Controller error: Invalid project key")
+		return ent.Project{}, fmt.Errorf("project key must be a letter followed by up to nine letters or digits")
+	}
+
+	project, err := c.GetProject(guildID, name)
+	if err != nil {
+		return ent.Project{}, err
+	}
+
+	if err := c.projectService.SetKey(guildID, project.ID, key); err != nil {
+		log.Println("Controller error:", err)
+		return ent.Project{}, err
+	}
+	project.Key = key
+	return project, nil
+}
+
+// GetProject finds a project by name
+func (c *ProjectController) GetProject(guildID, name string) (ent.Project, error) {
+	project, err := c.projectService.GetProject(guildID, name)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Project{}, err
+	}
+	return project, nil
+}
+
+// GetProjects lists the projects of a guild with their channel bindings
+func (c *ProjectController) GetProjects(guildID string) ([]ent.Project, error) {
+	projects, err := c.projectService.GetProjects(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return projects, nil
+}
+
+// BindChannel makes a channel or category default to the named project
+func (c *ProjectController) BindChannel(guildID, name, channelID string) (ent.Project, error) {
+	project, err := c.GetProject(guildID, name)
+	if err != nil {
+		return ent.Project{}, err
+	}
+
+	if err := c.projectService.BindChannel(guildID, project.ID, channelID); err != nil {
+		log.Println("Controller error:", err)
+		return ent.Project{}, err
+	}
+	return project, nil
+}
+
+// UnbindChannel removes the project binding of a channel or category
+func (c *ProjectController) UnbindChannel(guildID, channelID string) error {
+	if err := c.projectService.UnbindChannel(guildID, channelID); err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// FindProjectForChannels resolves the project a channel defaults to, given the channel
+// followed by its parents. It returns nil if none of them is bound to a project.
+func (c *ProjectController) FindProjectForChannels(guildID string, channelIDs []string) (*ent.Project, error) {
+	project, err := c.projectService.FindProjectForChannels(guildID, channelIDs)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return project, nil
+}
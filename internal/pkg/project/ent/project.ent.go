@@ -0,0 +1,22 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+)
+
+// Project represents a team or product whose tasks are kept apart from the rest of the guild, for GORM
+type Project struct {
+	gorm.Model
+	GuildID  string           `gorm:"not null;uniqueIndex:idx_project_name;uniqueIndex:idx_project_key,where:key <> ''" json:"guild_id"`
+	Name     string           `gorm:"not null;uniqueIndex:idx_project_name" json:"name"`
+	Key      string           `gorm:"uniqueIndex:idx_project_key,where:key <> ''" json:"key"` // Prefix of task references such as BE-42, empty if none
+	Bindings []ChannelBinding `json:"bindings"`
+}
+
+// ChannelBinding makes a channel, or every channel of a category, default to a project
+type ChannelBinding struct {
+	gorm.Model
+	GuildID   string `gorm:"not null;index" json:"guild_id"`
+	ProjectID uint   `gorm:"not null;index" json:"project_id"`
+	ChannelID string `gorm:"not null;uniqueIndex" json:"channel_id"` // Text channel or category
+}
@@ -0,0 +1,132 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"taskchord/internal/pkg/project/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+)
+
+type ProjectService struct {
+	db gossiper.Database
+}
+
+// NewProjectService initializes a new project service
+func NewProjectService(db gossiper.Database) *ProjectService {
+	return &ProjectService{db: db}
+}
+
+// CreateProject adds a project to a guild
+func (s *ProjectService) CreateProject(guildID, name string) (ent.Project, error) {
+	if _, err := s.GetProject(guildID, name); err == nil {
+		return ent.Project{}, fmt.Errorf("project %s already exists", name)
+	}
+
+	project := ent.Project{GuildID: guildID, Name: name}
+	err := s.db.GetDB().Create(&project).Error
+	return project, err
+}
+
+// SetKey changes the key prefix of a project. Its tasks take the new key, and tasks
+// created before the project had a key are numbered after the existing ones.
+func (s *ProjectService) SetKey(guildID string, projectID uint, key string) error {
+	var existing ent.Project
+	err := s.db.GetDB().Where("guild_id = ? AND key = ? AND id <> ?", guildID, key, projectID).First(&existing).Error
+	if err == nil {
+		return fmt.Errorf("key %s is already used by project %s", key, existing.Name)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&ent.Project{}).Where("id = ?", projectID).Update("key", key).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec(`UPDATE tasks SET project_number = numbered.number
+			FROM (
+				SELECT id, (SELECT COALESCE(MAX(project_number), 0) FROM tasks WHERE project_id = ?)
+					+ ROW_NUMBER() OVER (ORDER BY task_id_in_guild) AS number
+				FROM tasks
+				WHERE project_id = ? AND project_number = 0
+			) AS numbered
+			WHERE tasks.id = numbered.id`, projectID, projectID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&taskEnt.Task{}).Where("project_id = ?", projectID).Update("project_key", key).Error
+	})
+}
+
+// GetProject finds a project of a guild by name, ignoring case
+func (s *ProjectService) GetProject(guildID, name string) (ent.Project, error) {
+	var project ent.Project
+	err := s.db.GetDB().Where("guild_id = ? AND LOWER(name) = LOWER(?)", guildID, name).First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return project, fmt.Errorf("project %s does not exist", name)
+	}
+	return project, err
+}
+
+// GetProjects lists the projects of a guild with their channel bindings, by name
+func (s *ProjectService) GetProjects(guildID string) ([]ent.Project, error) {
+	var projects []ent.Project
+	err := s.db.GetDB().
+		Preload("Bindings").
+		Where("guild_id = ?", guildID).
+		Order("name").
+		Find(&projects).Error
+	return projects, err
+}
+
+// BindChannel makes a channel or category default to a project, replacing any previous binding
+func (s *ProjectService) BindChannel(guildID string, projectID uint, channelID string) error {
+	return s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("channel_id = ?", channelID).Delete(&ent.ChannelBinding{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&ent.ChannelBinding{GuildID: guildID, ProjectID: projectID, ChannelID: channelID}).Error
+	})
+}
+
+// UnbindChannel removes the project binding of a channel or category
+func (s *ProjectService) UnbindChannel(guildID, channelID string) error {
+	result := s.db.GetDB().Unscoped().
+		Where("guild_id = ? AND channel_id = ?", guildID, channelID).
+		Delete(&ent.ChannelBinding{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("channel %s is not bound to a project", channelID)
+	}
+	return nil
+}
+
+// FindProjectForChannels returns the project bound to the first of the given channels that has one.
+// Channels are tried in order, so a channel's own binding wins over its category's.
+func (s *ProjectService) FindProjectForChannels(guildID string, channelIDs []string) (*ent.Project, error) {
+	var bindings []ent.ChannelBinding
+	err := s.db.GetDB().Where("guild_id = ? AND channel_id IN ?", guildID, channelIDs).Find(&bindings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channelID := range channelIDs {
+		for _, binding := range bindings {
+			if binding.ChannelID != channelID {
+				continue
+			}
+			var project ent.Project
+			if err := s.db.GetDB().First(&project, binding.ProjectID).Error; err != nil {
+				return nil, err
+			}
+			return &project, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,34 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"taskchord/internal/pkg/search/ent"
+	"taskchord/internal/pkg/search/svc"
+)
+
+type SearchController struct {
+	searchService *svc.SearchService
+}
+
+// NewSearchController creates a new search controller
+func NewSearchController(searchService *svc.SearchService) *SearchController {
+	return &SearchController{searchService: searchService}
+}
+
+// Search validates a query and finds the best matching tasks a member may see
+func (c *SearchController) Search(guildID, userID string, roles []string, everything bool, query string) ([]ent.Result, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || len(query) > 200 {
+		log.Println("Controller error: Search queries must have between 1 and 200 characters")
+		return nil, fmt.Errorf("search queries must have between 1 and 200 characters")
+	}
+
+	results, err := c.searchService.Search(guildID, userID, roles, everything, query, 10)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return results, nil
+}
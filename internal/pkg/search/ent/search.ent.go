@@ -0,0 +1,12 @@
+package ent
+
+import (
+	taskEnt "taskchord/internal/pkg/task/ent"
+)
+
+// Result holds a task matching a search, with the passage that matched
+type Result struct {
+	Task    taskEnt.Task
+	Rank    float64 // Higher ranks match better
+	Snippet string  // Matched words are wrapped in ** for Discord
+}
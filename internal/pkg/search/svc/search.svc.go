@@ -0,0 +1,270 @@
+package svc
+
+import (
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"sort"
+	"strings"
+	"taskchord/internal/pkg/search/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+)
+
+// snippetOptions makes Postgres highlight matches the way Discord renders bold text
+const snippetOptions = "StartSel=**, StopSel=**, MaxWords=25, MinWords=8, MaxFragments=1"
+
+type SearchService struct {
+	db gossiper.Database
+}
+
+// NewSearchService initializes a new search service
+func NewSearchService(db gossiper.Database) *SearchService {
+	return &SearchService{db: db}
+}
+
+// postgres reports whether full-text search is available, falling back to LIKE otherwise
+func (s *SearchService) postgres() bool {
+	return s.db.GetDB().Dialector.Name() == "postgres"
+}
+
+// Migrate adds the search vectors of tasks and comments along with their indexes.
+// The columns are generated, so Postgres keeps them up to date on every write.
+func (s *SearchService) Migrate() error {
+	if !s.postgres() {
+		return nil
+	}
+
+	statements := []string{
+		`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_search ON tasks USING GIN (search_vector)`,
+		`ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(content, '')), 'C')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_comments_search ON comments USING GIN (search_vector)`,
+	}
+	for _, statement := range statements {
+		if err := s.db.GetDB().Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search finds the tasks of a guild matching a query in their title, description or comments,
+// the best matches first. Unless everything is visible, only the tasks a member authored,
+// executes or could claim for one of their roles are searched.
+func (s *SearchService) Search(guildID, userID string, roles []string, everything bool, query string, limit int) ([]ent.Result, error) {
+	if roles == nil {
+		roles = []string{}
+	}
+	scope := "t.guild_id = ? AND t.deleted_at IS NULL AND (? OR " + taskEnt.VisibleCondition("t") + ")"
+	scopeArgs := []interface{}{guildID, everything, userID, userID, roles}
+
+	var matches []match
+	var err error
+	if s.postgres() {
+		matches, err = s.fullTextMatches(scope, scopeArgs, query)
+	} else {
+		matches, err = s.likeMatches(scope, scopeArgs, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A task matching in several places keeps its best match
+	best := make(map[uint]match)
+	for _, m := range matches {
+		if current, found := best[m.TaskID]; !found || m.Rank > current.Rank {
+			best[m.TaskID] = m
+		}
+	}
+	ranked := make([]match, 0, len(best))
+	for _, m := range best {
+		ranked = append(ranked, m)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Rank != ranked[j].Rank {
+			return ranked[i].Rank > ranked[j].Rank
+		}
+		return ranked[i].TaskID > ranked[j].TaskID // Newer tasks first among equals
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	if len(ranked) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(ranked))
+	for _, m := range ranked {
+		ids = append(ids, m.TaskID)
+	}
+	var tasks []taskEnt.Task
+	if err := s.db.GetDB().Where("id IN ?", ids).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]taskEnt.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	results := make([]ent.Result, 0, len(ranked))
+	for _, m := range ranked {
+		if task, found := byID[m.TaskID]; found {
+			results = append(results, ent.Result{Task: task, Rank: m.Rank, Snippet: m.Snippet})
+		}
+	}
+	return results, nil
+}
+
+// match is a place where a task matched a query
+type match struct {
+	TaskID  uint
+	Rank    float64
+	Snippet string
+}
+
+// fullTextMatches searches the tsvector columns, ranking title matches above descriptions and comments
+func (s *SearchService) fullTextMatches(scope string, scopeArgs []interface{}, query string) ([]match, error) {
+	var matches []match
+	sql := `SELECT t.id AS task_id,
+			ts_rank(t.search_vector, q) AS rank,
+			ts_headline('simple', t.title || ' — ' || coalesce(t.description, ''), q, '` + snippetOptions + `') AS snippet
+		FROM tasks t, websearch_to_tsquery('simple', ?) q
+		WHERE t.search_vector @@ q AND ` + scope + `
+		UNION ALL
+		SELECT t.id AS task_id,
+			ts_rank(c.search_vector, q) AS rank,
+			ts_headline('simple', c.content, q, '` + snippetOptions + `') AS snippet
+		FROM comments c JOIN tasks t ON t.id = c.task_id, websearch_to_tsquery('simple', ?) q
+		WHERE c.deleted_at IS NULL AND c.search_vector @@ q AND ` + scope
+
+	args := append([]interface{}{query}, scopeArgs...)
+	args = append(args, query)
+	args = append(args, scopeArgs...)
+	err := s.db.GetDB().Raw(sql, args...).Scan(&matches).Error
+	return matches, err
+}
+
+// likeMatches searches with LIKE on databases without full-text search. Every word of the query
+// must appear and no word prefixed with - may; titles count more than descriptions and comments.
+func (s *SearchService) likeMatches(scope string, scopeArgs []interface{}, query string) ([]match, error) {
+	var words, excluded []string
+	for _, word := range strings.Fields(strings.ToLower(strings.ReplaceAll(query, `"`, " "))) {
+		if strings.HasPrefix(word, "-") {
+			if word = strings.TrimLeft(word, "-"); word != "" {
+				excluded = append(excluded, word)
+			}
+			continue
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	db := s.db.GetDB()
+	taskQuery := db.Table("tasks t").Select("t.id AS task_id, t.title, t.description").Where(scope, scopeArgs...)
+	commentQuery := db.Table("comments c").Joins("JOIN tasks t ON t.id = c.task_id").
+		Select("t.id AS task_id, c.content").
+		Where("c.deleted_at IS NULL").Where(scope, scopeArgs...)
+	for _, word := range words {
+		pattern := "%" + escapeLike(word) + "%"
+		taskQuery = taskQuery.Where("(LOWER(t.title) LIKE ? OR LOWER(t.description) LIKE ?)", pattern, pattern)
+		commentQuery = commentQuery.Where("LOWER(c.content) LIKE ?", pattern)
+	}
+	for _, word := range excluded {
+		pattern := "%" + escapeLike(word) + "%"
+		taskQuery = taskQuery.Where("LOWER(t.title) NOT LIKE ? AND LOWER(COALESCE(t.description, '')) NOT LIKE ?", pattern, pattern)
+		commentQuery = commentQuery.Where("LOWER(c.content) NOT LIKE ?", pattern)
+	}
+
+	var tasks []struct {
+		TaskID      uint
+		Title       string
+		Description string
+	}
+	if err := taskQuery.Scan(&tasks).Error; err != nil {
+		return nil, err
+	}
+	var comments []struct {
+		TaskID  uint
+		Content string
+	}
+	if err := commentQuery.Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	var matches []match
+	for _, task := range tasks {
+		rank := 0.0
+		for _, word := range words {
+			if strings.Contains(strings.ToLower(task.Title), word) {
+				rank += 1
+			} else {
+				rank += 0.4
+			}
+		}
+		matches = append(matches, match{TaskID: task.TaskID, Rank: rank, Snippet: highlight(task.Title+" — "+task.Description, words)})
+	}
+	for _, comment := range comments {
+		matches = append(matches, match{TaskID: comment.TaskID, Rank: 0.2 * float64(len(words)), Snippet: highlight(comment.Content, words)})
+	}
+	return matches, nil
+}
+
+// escapeLike keeps the wildcards of LIKE from being read in words typed by users
+func escapeLike(word string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(word)
+}
+
+// highlight cuts the passage around the first matched word out of a text and wraps the words in **
+func highlight(text string, words []string) string {
+	lower := strings.ToLower(text)
+	start := len(text)
+	for _, word := range words {
+		if index := strings.Index(lower, word); index >= 0 && index < start {
+			start = index
+		}
+	}
+	if start == len(text) {
+		start = 0
+	}
+
+	// Keep some context around the match, on rune boundaries
+	runes := []rune(text)
+	from := len([]rune(text[:start])) - 40
+	if from < 0 {
+		from = 0
+	}
+	to := from + 160
+	if to > len(runes) {
+		to = len(runes)
+	}
+	passage := string(runes[from:to])
+
+	for _, word := range words {
+		var marked strings.Builder
+		rest := passage
+		for {
+			// Offsets in the lower-cased text only carry over while lower-casing keeps the length
+			index := strings.Index(strings.ToLower(rest), word)
+			if index < 0 || len(strings.ToLower(rest)) != len(rest) {
+				marked.WriteString(rest)
+				break
+			}
+			marked.WriteString(rest[:index] + "**" + rest[index:index+len(word)] + "**")
+			rest = rest[index+len(word):]
+		}
+		passage = marked.String()
+	}
+
+	if from > 0 {
+		passage = "…" + passage
+	}
+	if to < len(runes) {
+		passage += "…"
+	}
+	return passage
+}
@@ -0,0 +1,106 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"taskchord/internal/pkg/stats/ent"
+	"taskchord/internal/pkg/stats/svc"
+	"time"
+)
+
+type StatsController struct {
+	statsService *svc.StatsService
+}
+
+// NewStatsController creates a new statistics controller
+func NewStatsController(statsService *svc.StatsService) *StatsController {
+	return &StatsController{statsService: statsService}
+}
+
+// GetGuildStats aggregates the tasks of a guild over the last given number of weeks.
+// Today is the current date at midnight UTC, the same way due dates are stored.
+func (c *StatsController) GetGuildStats(guildID string, weeks int, today time.Time) (ent.GuildStats, error) {
+	if weeks < 1 || weeks > 52 {
+		log.Println("Controller error: Weeks must be between 1 and 52")
+		return ent.GuildStats{}, fmt.Errorf("weeks must be between 1 and 52")
+	}
+
+	// Periods start on the Monday of the oldest week, matching Postgres date_trunc('week')
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	since := monday.AddDate(0, 0, -7*(weeks-1))
+
+	stats, err := c.statsService.GetGuildStats(guildID, since, today)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.GuildStats{}, err
+	}
+	return stats, nil
+}
+
+// GetWorkload counts the open tasks of each executor of a guild
+func (c *StatsController) GetWorkload(guildID string) ([]ent.GroupCount, error) {
+	workload, err := c.statsService.GetWorkload(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return workload, nil
+}
+
+// GetDailyFlow counts open and done tasks of a guild for every day of a period.
+// From and to are the local midnights of the first and the last day.
+func (c *StatsController) GetDailyFlow(guildID string, from, to time.Time) ([]ent.DayFlow, error) {
+	if err := validatePeriod(from, to); err != nil {
+		return nil, err
+	}
+
+	flow, err := c.statsService.GetDailyFlow(guildID, from, to)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return flow, nil
+}
+
+// GetBurndown counts open and done tasks due in a period for every day of it.
+// From and to are the local midnights of the first and the last day.
+func (c *StatsController) GetBurndown(guildID string, from, to time.Time) ([]ent.DayFlow, error) {
+	if err := validatePeriod(from, to); err != nil {
+		return nil, err
+	}
+
+	flow, err := c.statsService.GetBurndown(guildID, from, to)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return flow, nil
+}
+
+// GetMilestoneBurndown counts open and done tasks of a milestone for every day of a period.
+// From and to are the local midnights of the first and the last day of the milestone.
+func (c *StatsController) GetMilestoneBurndown(guildID string, milestoneID uint, from, to time.Time) ([]ent.DayFlow, error) {
+	if err := validatePeriod(from, to); err != nil {
+		return nil, err
+	}
+
+	flow, err := c.statsService.GetMilestoneBurndown(guildID, milestoneID, from, to)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return flow, nil
+}
+
+// validatePeriod checks that a chart period has at least two days and at most a year
+func validatePeriod(from, to time.Time) error {
+	if !from.Before(to) {
+		log.Println("Controller error: Period must end after it starts")
+		return fmt.Errorf("period must end after it starts")
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		log.Println("Controller error: Period must span at most a year")
+		return fmt.Errorf("period must span at most a year")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package ent
+
+import (
+	"time"
+)
+
+// WeekCount holds how many tasks were created and completed in one week
+type WeekCount struct {
+	Week      time.Time // Monday the week starts on
+	Created   int64
+	Completed int64
+}
+
+// GroupCount holds how many open tasks share one value, such as a priority or an executor
+type GroupCount struct {
+	Key   string
+	Count int64
+}
+
+// GuildStats holds the aggregated statistics of a guild's tasks
+type GuildStats struct {
+	Throughput       []WeekCount
+	AverageLeadTime  time.Duration // From creation to completion, over tasks completed in the period
+	OpenByPriority   []GroupCount
+	OpenByStatus     []GroupCount // States of the guild's workflow that are not terminal
+	OpenByExecutor   []GroupCount // An empty key stands for unclaimed role tasks
+	OpenCount        int64
+	OverdueCount     int64
+	CompletedInRange int64
+}
+
+// DayFlow holds how many tasks were open and done at the end of one day
+type DayFlow struct {
+	Day  time.Time
+	Open int64
+	Done int64
+}
@@ -0,0 +1,157 @@
+package svc
+
+import (
+	"database/sql"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"taskchord/internal/pkg/stats/ent"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+type StatsService struct {
+	db gossiper.Database
+}
+
+// NewStatsService initializes a new statistics service
+func NewStatsService(db gossiper.Database) *StatsService {
+	return &StatsService{db: db}
+}
+
+// GetGuildStats aggregates the tasks of a guild. Throughput and lead time cover the weeks
+// starting at since; open and overdue counts describe the guild as of today.
+func (s *StatsService) GetGuildStats(guildID string, since time.Time, today time.Time) (ent.GuildStats, error) {
+	var stats ent.GuildStats
+	db := s.db.GetDB()
+
+	var created, completed []struct {
+		Week  time.Time
+		Count int64
+	}
+	err := db.Model(&taskEnt.Task{}).
+		Select("date_trunc('week', created_at) AS week, COUNT(*) AS count").
+		Where("guild_id = ? AND created_at >= ?", guildID, since).
+		Group("week").
+		Scan(&created).Error
+	if err != nil {
+		return stats, err
+	}
+	err = db.Model(&taskEnt.Task{}).
+		Select("date_trunc('week', completed_at) AS week, COUNT(*) AS count").
+		Where("guild_id = ? AND completed_at >= ?", guildID, since).
+		Group("week").
+		Scan(&completed).Error
+	if err != nil {
+		return stats, err
+	}
+
+	// Every week of the period gets a row, even without any activity
+	weeks := make(map[string]*ent.WeekCount)
+	for week := since; week.Before(today.AddDate(0, 0, 1)); week = week.AddDate(0, 0, 7) {
+		stats.Throughput = append(stats.Throughput, ent.WeekCount{Week: week})
+	}
+	for i := range stats.Throughput {
+		weeks[stats.Throughput[i].Week.Format(time.DateOnly)] = &stats.Throughput[i]
+	}
+	for _, row := range created {
+		if week, found := weeks[row.Week.UTC().Format(time.DateOnly)]; found {
+			week.Created = row.Count
+		}
+	}
+	for _, row := range completed {
+		if week, found := weeks[row.Week.UTC().Format(time.DateOnly)]; found {
+			week.Completed = row.Count
+		}
+		stats.CompletedInRange += row.Count
+	}
+
+	var leadSeconds sql.NullFloat64
+	err = db.Model(&taskEnt.Task{}).
+		Select("AVG(EXTRACT(EPOCH FROM completed_at - created_at))").
+		Where("guild_id = ? AND completed_at >= ?", guildID, since).
+		Scan(&leadSeconds).Error
+	if err != nil {
+		return stats, err
+	}
+	if leadSeconds.Valid {
+		stats.AverageLeadTime = time.Duration(leadSeconds.Float64 * float64(time.Second))
+	}
+
+	err = db.Model(&taskEnt.Task{}).
+		Select("priority AS key, COUNT(*) AS count").
+		Where("guild_id = ? AND completed_at IS NULL", guildID).
+		Group("priority").
+		Order("count DESC").
+		Scan(&stats.OpenByPriority).Error
+	if err != nil {
+		return stats, err
+	}
+
+	err = db.Model(&taskEnt.Task{}).
+		Select("status AS key, COUNT(*) AS count").
+		Where("guild_id = ? AND completed_at IS NULL", guildID).
+		Group("status").
+		Order("count DESC").
+		Scan(&stats.OpenByStatus).Error
+	if err != nil {
+		return stats, err
+	}
+
+	stats.OpenByExecutor, err = s.GetWorkload(guildID)
+	if err != nil {
+		return stats, err
+	}
+	for _, group := range stats.OpenByExecutor {
+		stats.OpenCount += group.Count
+	}
+
+	err = db.Model(&taskEnt.Task{}).
+		Where("guild_id = ? AND completed_at IS NULL AND due_at < ?", guildID, today).
+		Count(&stats.OverdueCount).Error
+	return stats, err
+}
+
+// GetWorkload counts the open tasks of each executor, the busiest first
+func (s *StatsService) GetWorkload(guildID string) ([]ent.GroupCount, error) {
+	var workload []ent.GroupCount
+	err := s.db.GetDB().Model(&taskEnt.Task{}).
+		Select("executor_id AS key, COUNT(*) AS count").
+		Where("guild_id = ? AND completed_at IS NULL", guildID).
+		Group("executor_id").
+		Order("count DESC").
+		Scan(&workload).Error
+	return workload, err
+}
+
+// GetDailyFlow counts open and done tasks of a guild at the end of every day from the first to the last day
+func (s *StatsService) GetDailyFlow(guildID string, from, to time.Time) ([]ent.DayFlow, error) {
+	return s.dailyFlow(from, to, "t.guild_id = ?", guildID)
+}
+
+// GetBurndown counts open and done tasks due between the first and the last day, at the end of every day
+func (s *StatsService) GetBurndown(guildID string, from, to time.Time) ([]ent.DayFlow, error) {
+	// Due dates are stored at midnight UTC of their calendar day
+	firstDue := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	lastDue := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return s.dailyFlow(from, to, "t.guild_id = ? AND t.due_at BETWEEN ? AND ?", guildID, firstDue, lastDue)
+}
+
+// GetMilestoneBurndown counts open and done tasks of a milestone at the end of every day from the first to the last day
+func (s *StatsService) GetMilestoneBurndown(guildID string, milestoneID uint, from, to time.Time) ([]ent.DayFlow, error) {
+	return s.dailyFlow(from, to, "t.guild_id = ? AND t.milestone_id = ?", guildID, milestoneID)
+}
+
+// dailyFlow counts the tasks matching the scope that existed at the end of every day,
+// splitting them by whether they were completed by then
+func (s *StatsService) dailyFlow(from, to time.Time, scope string, args ...interface{}) ([]ent.DayFlow, error) {
+	var flow []ent.DayFlow
+	query := `SELECT d.day AS day,
+		COUNT(t.id) FILTER (WHERE t.completed_at IS NULL OR t.completed_at >= d.day + interval '1 day') AS open,
+		COUNT(t.id) FILTER (WHERE t.completed_at < d.day + interval '1 day') AS done
+	FROM generate_series(?::timestamptz, ?::timestamptz, interval '1 day') AS d(day)
+	LEFT JOIN tasks t ON t.created_at < d.day + interval '1 day' AND t.deleted_at IS NULL AND ` + scope + `
+	GROUP BY d.day
+	ORDER BY d.day`
+
+	err := s.db.GetDB().Raw(query, append([]interface{}{from, to}, args...)...).Scan(&flow).Error
+	return flow, err
+}
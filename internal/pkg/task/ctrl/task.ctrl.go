@@ -3,68 +3,226 @@ package ctrl
 import (
 	"fmt"
 	"log"
+	"slices"
+	"strings"
+	guildSvc "taskchord/internal/pkg/guild/svc"
+	prioritySvc "taskchord/internal/pkg/priority/svc"
 	"taskchord/internal/pkg/task/ent"
 	"taskchord/internal/pkg/task/svc"
+	"time"
+)
+
+const (
+	// duplicateThreshold is the trigram similarity from which an open task counts as a likely duplicate
+	duplicateThreshold = 0.5
+	// duplicateLimit keeps a watch button for every duplicate in one row next to the create button
+	duplicateLimit = 4
+	// bulkLimit is the most tasks one bulk operation may change
+	bulkLimit = 100
 )
 
 type TaskController struct {
-	taskService *svc.TaskService
+	taskService     *svc.TaskService
+	guildService    *guildSvc.GuildService
+	priorityService *prioritySvc.PriorityService
 }
 
 // NewTaskController creates a new task controller
-func NewTaskController(taskService *svc.TaskService) *TaskController {
-	return &TaskController{taskService: taskService}
+func NewTaskController(taskService *svc.TaskService, guildService *guildSvc.GuildService, priorityService *prioritySvc.PriorityService) *TaskController {
+	return &TaskController{taskService: taskService, guildService: guildService, priorityService: priorityService}
+}
+
+// resolvePriority checks a priority against the guild's scale and returns its stored spelling.
+// An empty priority stands for the default level of the scale.
+func (c *TaskController) resolvePriority(guildID, priority string) (string, error) {
+	scale, err := c.priorityService.GetScale(guildID)
+	if err != nil {
+		return "", err
+	}
+	if priority == "" {
+		return scale.Default().Name, nil
+	}
+
+	level, ok := scale.Find(priority)
+	if !ok {
+		return "", fmt.Errorf("priority %s is not on the scale of this server", priority)
+	}
+	return level.Name, nil
 }
 
 // CreateTask delegates the task creation to the service layer
-func (c *TaskController) CreateTask(guildID, userID, title, description, priority string, executorID string) (int, error) {
-	// Call the service layer to create the task and return the taskIdInGuild
-	taskIdInGuild, err := c.taskService.CreateTask(guildID, userID, title, description, priority, executorID)
+func (c *TaskController) CreateTask(guildID, userID, title, description, priority string, executorID string, dueAt *time.Time, estimate int, milestoneID, projectID *uint, tags, checklist []string) (ent.Task, error) {
+	if estimate < 0 {
+		log.Println("Controller error: Estimate cannot be negative")
+		return ent.Task{}, fmt.Errorf("estimate cannot be negative")
+	}
+
+	priority, err := c.resolvePriority(guildID, priority)
 	if err != nil {
 		log.Println("Controller error:", err)
-		return 0, err
+		return ent.Task{}, err
 	}
 
-	// Return the taskIdInGuild along with nil error
-	return taskIdInGuild, nil
+	tags, err = normalizeTags(tags)
+	if err == nil {
+		err = validateChecklist(checklist)
+	}
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, err
+	}
+
+	// Call the service layer to create the task and return it
+	task, err := c.taskService.CreateTask(guildID, userID, title, description, priority, executorID, dueAt, estimate, milestoneID, projectID, tags, checklist)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, err
+	}
+
+	// Return the task along with nil error
+	return task, nil
+}
+
+// CreateTaskForRole creates a task for a role, letting the guild's assignment strategy pick the executor
+func (c *TaskController) CreateTaskForRole(guildID, userID, title, description, priority, roleID string, dueAt *time.Time, estimate int, milestoneID, projectID *uint, tags, checklist []string, members []string) (ent.Task, error) {
+	priority, err := c.resolvePriority(guildID, priority)
+	if err == nil {
+		tags, err = normalizeTags(tags)
+	}
+	if err == nil {
+		err = validateChecklist(checklist)
+	}
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, err
+	}
+
+	settings, err := c.guildService.GetSettings(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, err
+	}
+
+	task, err := c.taskService.CreateTaskForRole(guildID, userID, title, description, priority, roleID, dueAt, estimate, milestoneID, projectID, tags, checklist, settings.AssignStrategy, members)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, err
+	}
+
+	return task, nil
+}
+
+// ClaimTask lets a role member take an unclaimed task from the role queue
+func (c *TaskController) ClaimTask(guildID, userID, id string, roles []string) (ent.Task, error) {
+	if id == "" {
+		log.Println("Controller error: Task ID is required")
+		return ent.Task{}, fmt.Errorf("task ID is required")
+	}
+
+	task, err := c.taskService.ClaimTask(guildID, userID, id, roles)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, err
+	}
+
+	return task, nil
 }
 
-func (c *TaskController) UpdateTask(guildID, userID, title, description, priority, executorID, id string) (int, error) {
+func (c *TaskController) UpdateTask(guildID, userID, title, description, priority, executorID, status, id string, dueAt *time.Time, estimate int, milestoneID *uint, tags []string) (ent.Task, error) {
 	// Validate the task ID
 	if id == "" {
 		log.Println("Controller error: Task ID is required")
-		return 0, fmt.Errorf("task ID is required")
+		return ent.Task{}, fmt.Errorf("task ID is required")
 	}
 
 	// Ensure at least one field is provided for updating
-	if title == "" && description == "" && priority == "" && executorID == "" {
-		log.Println("Controller error: At least one field (title, description, priority, or executor) must be provided for update")
-		return 0, fmt.Errorf("at least one field (title, description, priority, or executor) must be provided for update")
+	if title == "" && description == "" && priority == "" && executorID == "" && status == "" && dueAt == nil && estimate == 0 && milestoneID == nil && tags == nil {
+		log.Println("Controller error: At least one field (title, description, priority, executor, status, due date, estimate, milestone, or tags) must be provided for update")
+		return ent.Task{}, fmt.Errorf("at least one field (title, description, priority, executor, status, due date, estimate, milestone, or tags) must be provided for update")
 	}
 
-	// Optional: Validate priority if provided
+	if tags != nil {
+		var err error
+		tags, err = normalizeTags(tags)
+		if err != nil {
+			log.Println("Controller error:", err)
+			return ent.Task{}, err
+		}
+	}
+
+	// Optional: Validate priority against the guild's scale if provided
 	if priority != "" {
-		validPriorities := map[string]bool{"High": true, "Medium": true, "Low": true}
-		if !validPriorities[priority] {
-			log.Println("Controller error: Invalid priority value")
-			return 0, fmt.Errorf("invalid priority value")
+		var err error
+		priority, err = c.resolvePriority(guildID, priority)
+		if err != nil {
+			log.Println("Controller error:", err)
+			return ent.Task{}, err
 		}
 	}
 
+	if estimate < 0 {
+		log.Println("Controller error: Estimate cannot be negative")
+		return ent.Task{}, fmt.Errorf("estimate cannot be negative")
+	}
+
+	// The status is checked against the guild's workflow by the service layer
 	// Call the service layer to update the task
-	taskIdInGuild, err := c.taskService.UpdateTask(guildID, userID, title, description, priority, executorID, id)
+	task, err := c.taskService.UpdateTask(guildID, userID, title, description, priority, executorID, status, id, dueAt, estimate, milestoneID, tags)
 	if err != nil {
 		log.Println("Controller error:", err)
-		return 0, err
+		return ent.Task{}, err
 	}
 
-	// Return the updated task ID along with nil error
-	return taskIdInGuild, nil
+	// Return the updated task along with nil error
+	return task, nil
+}
+
+// GetTasksByUserID retrieves tasks for a specific user, including queued tasks of their roles,
+// optionally limited to one project, one status and the tasks matching filters
+func (c *TaskController) GetTasksByUserID(guildID string, userID string, id string, roles []string, projectID *uint, status string, filters ...svc.Filter) ([]ent.Task, error) {
+	return c.taskService.GetTasksByUserID(guildID, userID, id, roles, projectID, status, filters...)
+}
+
+// CompileQuery parses a task query typed by a user, such as status:open tag:backend, into a filter.
+// Today is the user's current date at midnight UTC, the same way due dates are stored.
+func (c *TaskController) CompileQuery(guildID, userID, query string, today time.Time) (svc.Filter, error) {
+	if len(query) > 500 {
+		log.Println("Controller error: Queries can have at most 500 characters")
+		return nil, fmt.Errorf("queries can have at most 500 characters")
+	}
+
+	parsed, err := ent.ParseQuery(query)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+
+	filter, err := c.taskService.CompileQuery(guildID, userID, parsed, today)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return filter, nil
+}
+
+// GetTask retrieves a task of a guild by its reference, such as 42 or BE-42
+func (c *TaskController) GetTask(guildID, id string) (ent.Task, error) {
+	return c.taskService.GetTask(guildID, id)
 }
 
-// GetTasksByUserID retrieves tasks for a specific user
-func (c *TaskController) GetTasksByUserID(guildID string, userID string, id string) ([]ent.Task, error) {
-	return c.taskService.GetTasksByUserID(guildID, userID, id)
+// GetMergeTarget retrieves the task that a merged task was folded into
+func (c *TaskController) GetMergeTarget(guildID, id string) (ent.Task, error) {
+	return c.taskService.GetMergeTarget(guildID, id)
+}
+
+// GetBoard retrieves the open and recently completed tasks of a guild or project that a member can see, matching the filters
+func (c *TaskController) GetBoard(guildID, userID string, roles []string, everything bool, projectID *uint, doneSince time.Time, filters ...svc.Filter) ([]ent.Task, error) {
+	tasks, err := c.taskService.GetBoard(guildID, userID, roles, everything, projectID, doneSince, filters...)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return tasks, nil
 }
 
 func (c *TaskController) DeleteTask(guildID string, userID string, id string) (string, error) {
@@ -76,3 +234,256 @@ func (c *TaskController) DeleteTask(guildID string, userID string, id string) (s
 
 	return taskIdInGuild, nil
 }
+
+// SelectBulkTasks picks the tasks of a bulk operation, either from a list such as 3,5,9-12 or with a task query.
+// Members who do not manage the guild can only pick the tasks they created.
+func (c *TaskController) SelectBulkTasks(guildID, userID string, manager bool, list, query string, today time.Time) ([]ent.Task, error) {
+	if (list == "") == (query == "") {
+		log.Println("Controller error: Either a task list or a query is required")
+		return nil, fmt.Errorf("give either a list of tasks or a query")
+	}
+
+	var filter svc.Filter
+	if list != "" {
+		parsed, err := ent.ParseTaskList(list, bulkLimit)
+		if err != nil {
+			log.Println("Controller error:", err)
+			return nil, err
+		}
+		filter = svc.ListFilter(parsed)
+	} else {
+		var err error
+		filter, err = c.CompileQuery(guildID, userID, query, today)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	authorID := userID
+	if manager {
+		authorID = ""
+	}
+	tasks, err := c.taskService.SelectTasks(guildID, authorID, bulkLimit+1, filter)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		log.Println("Controller error: No tasks selected")
+		return nil, fmt.Errorf("no tasks you may change match")
+	}
+	if len(tasks) > bulkLimit {
+		log.Println("Controller error: Too many tasks selected")
+		return nil, fmt.Errorf("more than %d tasks match; narrow the selection down", bulkLimit)
+	}
+	return tasks, nil
+}
+
+// BulkApply checks a bulk change and makes it to the given tasks in one transaction.
+// A dry run returns the tasks as they would end up without changing anything.
+func (c *TaskController) BulkApply(guildID, userID string, manager bool, ids []uint, change ent.BulkChange, dryRun bool) ([]ent.Task, error) {
+	var err error
+	switch change.Action {
+	case ent.Close, ent.Delete:
+	case ent.Reassign:
+		if change.ExecutorID == "" {
+			err = fmt.Errorf("reassigning needs an executor")
+		}
+	case ent.Reprioritize:
+		if change.Priority == "" {
+			err = fmt.Errorf("reprioritizing needs a priority")
+			break
+		}
+		var priority string
+		priority, err = c.resolvePriority(guildID, string(change.Priority))
+		change.Priority = ent.Priority(priority)
+	case ent.Retag:
+		if change.AddTags, err = normalizeTags(change.AddTags); err != nil {
+			break
+		}
+		if change.RemoveTags, err = normalizeTags(change.RemoveTags); err != nil {
+			break
+		}
+		if len(change.AddTags) == 0 && len(change.RemoveTags) == 0 {
+			err = fmt.Errorf("retagging needs tags to add or remove")
+		}
+	default:
+		err = fmt.Errorf("unknown bulk action %s", change.Action)
+	}
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+
+	authorID := userID
+	if manager {
+		authorID = ""
+	}
+	tasks, err := c.taskService.BulkApply(guildID, authorID, ids, change, dryRun)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// AddChecklistItem appends an item to the checklist of a task
+func (c *TaskController) AddChecklistItem(taskID uint, text string) (ent.ChecklistItem, error) {
+	text = strings.TrimSpace(text)
+	if err := validateChecklist([]string{text}); err != nil {
+		log.Println("Controller error:", err)
+		return ent.ChecklistItem{}, err
+	}
+
+	item, err := c.taskService.AddChecklistItem(taskID, text)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.ChecklistItem{}, err
+	}
+	return item, nil
+}
+
+// ToggleChecklistItem checks or unchecks an item of a checklist, counting from 1
+func (c *TaskController) ToggleChecklistItem(taskID uint, number int) (ent.ChecklistItem, error) {
+	item, err := c.taskService.ToggleChecklistItem(taskID, number)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.ChecklistItem{}, err
+	}
+	return item, nil
+}
+
+// RemoveChecklistItem deletes an item of a checklist, counting from 1
+func (c *TaskController) RemoveChecklistItem(taskID uint, number int) (ent.ChecklistItem, error) {
+	item, err := c.taskService.RemoveChecklistItem(taskID, number)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.ChecklistItem{}, err
+	}
+	return item, nil
+}
+
+// normalizeTags lower-cases tag names, joins their words with dashes and drops repeats
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))), "-")
+		if tag == "" {
+			continue
+		}
+		if len(tag) > 30 {
+			return nil, fmt.Errorf("tags must have at most 30 characters")
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > 10 {
+		return nil, fmt.Errorf("a task can have at most 10 tags")
+	}
+	return normalized, nil
+}
+
+// validateChecklist checks the items of a checklist
+func validateChecklist(items []string) error {
+	if len(items) > 25 {
+		return fmt.Errorf("a checklist can have at most 25 items")
+	}
+	for _, item := range items {
+		if item == "" || len(item) > 200 {
+			return fmt.Errorf("checklist items must have between 1 and 200 characters")
+		}
+	}
+	return nil
+}
+
+// CloneTask copies a task into a new task of the given author, returning the original and the copy
+func (c *TaskController) CloneTask(guildID, userID, id string, includeChecklist, includeComments bool) (ent.Task, ent.Task, error) {
+	source, clone, err := c.taskService.CloneTask(guildID, userID, id, includeChecklist, includeComments)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, ent.Task{}, err
+	}
+	return source, clone, nil
+}
+
+// MergeTasks folds the source task into the target task, returning both as they are after the merge
+func (c *TaskController) MergeTasks(guildID, sourceID, targetID string) (ent.Task, ent.Task, error) {
+	if sourceID == "" || targetID == "" {
+		log.Println("Controller error: Source and target task IDs are required")
+		return ent.Task{}, ent.Task{}, fmt.Errorf("source and target task IDs are required")
+	}
+
+	source, target, err := c.taskService.MergeTasks(guildID, sourceID, targetID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, ent.Task{}, err
+	}
+	return source, target, nil
+}
+
+// MoveTask moves a task to another project, or out of its project if projectID is nil
+func (c *TaskController) MoveTask(guildID, id string, projectID *uint) (ent.Task, error) {
+	task, err := c.taskService.MoveTask(guildID, id, projectID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Task{}, err
+	}
+	return task, nil
+}
+
+// AddComment validates and leaves a comment on a task
+func (c *TaskController) AddComment(taskID uint, userID, content string) (ent.Comment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || len(content) > 1000 {
+		log.Println("Controller error: Comments must have between 1 and 1000 characters")
+		return ent.Comment{}, fmt.Errorf("comments must have between 1 and 1000 characters")
+	}
+
+	comment, err := c.taskService.AddComment(taskID, userID, content)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Comment{}, err
+	}
+	return comment, nil
+}
+
+// GetComments retrieves the comments of a task, the oldest first
+func (c *TaskController) GetComments(taskID uint) ([]ent.Comment, error) {
+	return c.taskService.GetComments(taskID)
+}
+
+// ToggleWatch starts or stops a user watching a task and reports whether they watch it now
+func (c *TaskController) ToggleWatch(taskID uint, userID string) (bool, error) {
+	watching, err := c.taskService.ToggleWatch(taskID, userID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return false, err
+	}
+	return watching, nil
+}
+
+// WatchTask makes a user watch a task, leaving it as is when they already do
+func (c *TaskController) WatchTask(taskID uint, userID string) error {
+	if err := c.taskService.WatchTask(taskID, userID); err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// FindDuplicates lists the open tasks of a guild that are likely duplicates of a new task and visible to the user,
+// or all of them when everything is set
+func (c *TaskController) FindDuplicates(guildID, userID string, roles []string, everything bool, title, description string) ([]ent.Duplicate, error) {
+	duplicates, err := c.taskService.FindDuplicates(guildID, userID, roles, everything, title, description, duplicateThreshold, duplicateLimit)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+// GetWatchers lists the users watching a task
+func (c *TaskController) GetWatchers(taskID uint) ([]string, error) {
+	return c.taskService.GetWatchers(taskID)
+}
@@ -0,0 +1,121 @@
+package ent
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BulkAction is what a bulk operation does to each of its tasks
+type BulkAction string
+
+const (
+	Close        BulkAction = "close"
+	Reassign     BulkAction = "reassign"
+	Retag        BulkAction = "retag"
+	Reprioritize BulkAction = "reprioritize"
+	Delete       BulkAction = "delete"
+)
+
+// BulkChange describes a bulk operation
+type BulkChange struct {
+	Action     BulkAction
+	ExecutorID string   // New executor of reassigned tasks
+	Priority   Priority // New priority of reprioritized tasks
+	AddTags    []string // Tags given to retagged tasks
+	RemoveTags []string // Tags taken from retagged tasks
+}
+
+// maxTaskNumber is the largest task number a list may name
+const maxTaskNumber = math.MaxInt32
+
+// TaskList names tasks the way users list them, such as 3,5,9-12 or BE-4,BE-7
+type TaskList struct {
+	Numbers []int            // Numbers of tasks within the guild
+	Keyed   map[string][]int // Numbers of tasks within projects, by project key
+}
+
+// ParseTaskList reads a comma separated list of task references and ranges of guild numbers.
+// Lists naming more than limit tasks are rejected, so a typo like 1-100000 cannot select the whole guild.
+// A task named twice, e.g. by overlapping ranges, is listed and counted once.
+func ParseTaskList(input string, limit int) (TaskList, error) {
+	list := TaskList{Keyed: make(map[string][]int)}
+	seen := make(map[string]bool)
+	count := 0
+
+	// add lists a task unless it already is, failing once the list grows past the limit
+	add := func(key string, number int) error {
+		reference := fmt.Sprintf("%s-%d", key, number)
+		if seen[reference] {
+			return nil
+		}
+		if count >= limit {
+			return fmt.Errorf("lists can name at most %d tasks", limit)
+		}
+		seen[reference] = true
+		count++
+		if key == "" {
+			list.Numbers = append(list.Numbers, number)
+		} else {
+			list.Keyed[key] = append(list.Keyed[key], number)
+		}
+		return nil
+	}
+
+	for _, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if first, last, isRange := parseRange(item); isRange {
+			if first < 1 || last < first || last > maxTaskNumber {
+				return TaskList{}, fmt.Errorf("invalid range %s", item)
+			}
+			// Checked before counting, so the loop below never runs more than limit times
+			if last-first >= limit {
+				return TaskList{}, fmt.Errorf("lists can name at most %d tasks", limit)
+			}
+			for number := first; number <= last; number++ {
+				if err := add("", number); err != nil {
+					return TaskList{}, err
+				}
+			}
+			continue
+		}
+
+		key, number, err := ParseReference(item)
+		if err != nil {
+			return TaskList{}, err
+		}
+		if number > maxTaskNumber {
+			return TaskList{}, fmt.Errorf("invalid task reference %s", item)
+		}
+		if err := add(key, number); err != nil {
+			return TaskList{}, err
+		}
+	}
+
+	if count == 0 {
+		return TaskList{}, fmt.Errorf("the list names no tasks")
+	}
+	return list, nil
+}
+
+// parseRange reads a range of guild numbers such as 9-12. Project references like BE-4 are not ranges.
+func parseRange(item string) (int, int, bool) {
+	from, to, found := strings.Cut(strings.TrimPrefix(item, "#"), "-")
+	if !found {
+		return 0, 0, false
+	}
+	first, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, false
+	}
+	last, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(to), "#"))
+	if err != nil {
+		return 0, 0, false
+	}
+	return first, last, true
+}
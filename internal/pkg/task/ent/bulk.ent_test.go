@@ -0,0 +1,62 @@
+package ent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTaskList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		limit   int
+		numbers []int
+		keyed   map[string][]int
+		wantErr bool
+	}{
+		{name: "numbers and ranges", input: "3, 5,#9-#12", limit: 100, numbers: []int{3, 5, 9, 10, 11, 12}, keyed: map[string][]int{}},
+		{name: "project references", input: "BE-4,be-7,2", limit: 100, numbers: []int{2}, keyed: map[string][]int{"BE": {4, 7}}},
+		{name: "overlapping ranges count once", input: "1-5,3-7,4", limit: 7, numbers: []int{1, 2, 3, 4, 5, 6, 7}, keyed: map[string][]int{}},
+		{name: "repeated ranges stay within the limit", input: "1-60,1-60", limit: 100, numbers: rangeOf(1, 60), keyed: map[string][]int{}},
+		{name: "exactly the limit", input: "1-100", limit: 100, numbers: rangeOf(1, 100), keyed: map[string][]int{}},
+		{name: "one past the limit", input: "1-101", limit: 100, wantErr: true},
+		{name: "past the limit across items", input: "1-99,200,300", limit: 100, wantErr: true},
+		{name: "huge range after a number", input: "5,1-9223372036854775807", limit: 100, wantErr: true},
+		{name: "huge range near the top", input: "9223372036854775806-9223372036854775807", limit: 100, wantErr: true},
+		{name: "number beyond the cap", input: "4294967296", limit: 100, wantErr: true},
+		{name: "reversed range", input: "12-9", limit: 100, wantErr: true},
+		{name: "range from zero", input: "0-3", limit: 100, wantErr: true},
+		{name: "empty list", input: " , ", limit: 100, wantErr: true},
+		{name: "not a reference", input: "abc", limit: 100, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list, err := ParseTaskList(test.input, test.limit)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTaskList(%q) = %v, want an error", test.input, list)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTaskList(%q) failed: %v", test.input, err)
+			}
+			if !reflect.DeepEqual(list.Numbers, test.numbers) {
+				t.Errorf("numbers = %v, want %v", list.Numbers, test.numbers)
+			}
+			if !reflect.DeepEqual(list.Keyed, test.keyed) {
+				t.Errorf("keyed = %v, want %v", list.Keyed, test.keyed)
+			}
+		})
+	}
+}
+
+// rangeOf lists the numbers from first to last
+func rangeOf(first, last int) []int {
+	var numbers []int
+	for number := first; number <= last; number++ {
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
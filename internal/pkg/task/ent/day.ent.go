@@ -0,0 +1,45 @@
+package ent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dayLayout is the format users type days in, such as due dates
+const dayLayout = "2006-01-02"
+
+// Day turns a moment into its calendar day. Due dates are calendar days, stored as midnight UTC.
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ParseDay resolves a day typed by a user: YYYY-MM-DD, today, tomorrow, yesterday, or a number of
+// days or weeks from today such as 7d, +2w or -3d. Today is the calendar day of the user, see Day.
+func ParseDay(value string, today time.Time) (time.Time, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if len(value) > 1 && (strings.HasSuffix(value, "d") || strings.HasSuffix(value, "w")) {
+		if amount, err := strconv.Atoi(strings.TrimPrefix(value[:len(value)-1], "+")); err == nil {
+			if strings.HasSuffix(value, "w") {
+				amount *= 7
+			}
+			return today.AddDate(0, 0, amount), nil
+		}
+	}
+
+	day, err := time.ParseInLocation(dayLayout, value, time.UTC)
+	if err != nil {
+		return day, fmt.Errorf("%s is not a date such as 2024-12-31, today or 7d", value)
+	}
+	return day, nil
+}
@@ -0,0 +1,171 @@
+package ent
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// QueryFields lists the fields a task query can filter on. Fields in the same entry are aliases.
+var QueryFields = map[string]string{
+	"status":    "status",
+	"is":        "status",
+	"priority":  "priority",
+	"assignee":  "assignee",
+	"executor":  "assignee",
+	"author":    "author",
+	"due":       "due",
+	"tag":       "tag",
+	"project":   "project",
+	"milestone": "milestone",
+	"role":      "role",
+}
+
+// Comparison is how a query clause compares a field with its value
+type Comparison string
+
+const (
+	Equal        Comparison = ":"
+	Less         Comparison = "<"
+	LessEqual    Comparison = "<="
+	Greater      Comparison = ">"
+	GreaterEqual Comparison = ">="
+)
+
+// Clause is one condition of a task query, such as priority:>=medium or -tag:wontfix.
+// Clauses without a field match words in the title and description.
+type Clause struct {
+	Field      string // One of the values of QueryFields, empty for free text
+	Comparison Comparison
+	Value      string
+	Negated    bool
+}
+
+// Query is a parsed task filter; a task matches when it matches every clause
+type Query struct {
+	Clauses []Clause
+}
+
+// String renders a clause the way users type it
+func (c Clause) String() string {
+	value := c.Value
+	if strings.ContainsAny(value, " \t") {
+		value = `"` + value + `"`
+	}
+	prefix := ""
+	if c.Negated {
+		prefix = "-"
+	}
+	if c.Field == "" {
+		return prefix + value
+	}
+	comparison := string(c.Comparison)
+	if c.Comparison != Equal {
+		comparison = ":" + comparison
+	}
+	return prefix + c.Field + comparison + value
+}
+
+// ParseQuery parses a task filter such as
+//
+//	status:open priority:>=medium assignee:@me due:<7d tag:backend -tag:wontfix "login"
+//
+// Words are separated by spaces, double quotes group words, and a leading dash negates a clause.
+func ParseQuery(input string) (Query, error) {
+	var query Query
+
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return query, err
+	}
+
+	for _, token := range tokens {
+		clause := Clause{Comparison: Equal}
+		text := token.text
+		if !token.quoted && strings.HasPrefix(text, "-") && len(text) > 1 {
+			clause.Negated = true
+			text = text[1:]
+		}
+
+		name, value, found := strings.Cut(text, ":")
+		if token.quoted || !found {
+			clause.Value = strings.Trim(text, `"`)
+			query.Clauses = append(query.Clauses, clause)
+			continue
+		}
+
+		field, known := QueryFields[strings.ToLower(name)]
+		if !known {
+			return query, fmt.Errorf("unknown field %s", name)
+		}
+		clause.Field = field
+
+		for _, comparison := range []Comparison{LessEqual, GreaterEqual, Less, Greater} {
+			if strings.HasPrefix(value, string(comparison)) {
+				clause.Comparison = comparison
+				value = value[len(comparison):]
+				break
+			}
+		}
+		value = strings.Trim(value, `"`)
+		if value == "" {
+			return query, fmt.Errorf("field %s needs a value", name)
+		}
+		if clause.Comparison != Equal && field != "priority" && field != "due" {
+			return query, fmt.Errorf("field %s cannot be compared with %s", name, clause.Comparison)
+		}
+		clause.Value = value
+		query.Clauses = append(query.Clauses, clause)
+	}
+
+	if len(query.Clauses) > 20 {
+		return query, fmt.Errorf("a query can have at most 20 clauses")
+	}
+	return query, nil
+}
+
+// queryToken is a word of a query; quoted tokens are always free text
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeQuery splits a query into words, keeping quoted phrases together. A field value may be
+// quoted as well, as in project:"Mobile App".
+func tokenizeQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	var current strings.Builder
+	quoted, inQuotes, started := false, false, false
+
+	flush := func() {
+		if started {
+			tokens = append(tokens, queryToken{text: current.String(), quoted: quoted})
+		}
+		current.Reset()
+		quoted, started = false, false
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			if !inQuotes && !started {
+				quoted = true // The whole word is a phrase
+			}
+			if !quoted {
+				current.WriteRune(r) // Quotes around a field value are stripped later
+			}
+			inQuotes = !inQuotes
+			started = true
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("a quote is not closed")
+	}
+	flush()
+	return tokens, nil
+}
@@ -0,0 +1,42 @@
+package ent
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Duplicate is an open task that resembles a task about to be created
+type Duplicate struct {
+	Task  Task
+	Score float64 // Similarity between 0 and 1
+}
+
+// Trigrams splits text into the set of its three-letter sequences the way pg_trgm does:
+// case and punctuation are ignored, and every word is padded with two spaces in front and one behind.
+func Trigrams(text string) map[string]struct{} {
+	trigrams := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for index := 0; index+3 <= len(padded); index++ {
+			trigrams[string(padded[index:index+3])] = struct{}{}
+		}
+	}
+	return trigrams
+}
+
+// Similarity is the share of trigrams two texts have in common, from 0 for nothing to 1 for all
+func Similarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range a {
+		if _, found := b[trigram]; found {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -1,40 +1,139 @@
 package ent
 
 import (
+	"fmt"
 	"gorm.io/gorm"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Priority represents the allowed values for the Priority field.
+// Priority names a level of the guild's priority scale, such as High or P0
 type Priority string
 
-const (
-	High   Priority = "High"
-	Medium Priority = "Medium"
-	Low    Priority = "Low"
-)
+// Status names a state of the guild's workflow, such as Open, Review or Done
+type Status string
+
+// Task represents a task model for GORM
+type Task struct {
+	gorm.Model
+	TaskIdInGuild int             `gorm:"not null" json:"task_id_in_guild"` // Task ID within a guild
+	UserID        string          `gorm:"not null" json:"user_id"`
+	ExecutorID    string          `gorm:"not null" json:"executor_id"`              // Empty while the task waits in a role queue
+	RoleID        string          `gorm:"index" json:"role_id"`                     // Role the task was assigned to, if any
+	GuildID       string          `gorm:"not null;index" json:"guild_id"`           // Indexed for grouping tasks by guild
+	Title         string          `gorm:"not null" json:"title"`                    // Title of the task
+	Priority      Priority        `gorm:"type:varchar(20)" json:"priority"`         // Priority of the task, a level of the guild's scale
+	Description   string          `gorm:"type:text" json:"description"`             // Task description
+	Status        Status          `gorm:"type:varchar(20)" json:"status"`           // Status of the task, a state of the guild's workflow
+	DueAt         *time.Time      `gorm:"index" json:"due_at"`                      // Start of the day the task is due
+	CompletedAt   *time.Time      `json:"completed_at"`                             // When the task reached the terminal state, nil while it is open
+	Estimate      int             `json:"estimate"`                                 // Estimated work in minutes, 0 if unknown
+	MilestoneID   *uint           `gorm:"index" json:"milestone_id"`                // Primary key of the milestone, nil if none
+	ProjectID     *uint           `gorm:"index" json:"project_id"`                  // Primary key of the project, nil if none
+	ProjectKey    string          `gorm:"index:idx_task_key" json:"project_key"`    // Key prefix of the project, empty if it has none
+	ProjectNumber int             `gorm:"index:idx_task_key" json:"project_number"` // Task number within the project, 0 outside projects
+	Tags          []Tag           `gorm:"foreignKey:TaskID" json:"tags"`
+	Checklist     []ChecklistItem `gorm:"foreignKey:TaskID" json:"checklist"`
+	MergedIntoID  *uint           `json:"merged_into_id"` // Primary key of the task this one was merged into, nil unless merged
+}
 
-// String method for the Priority type, to provide string representation of each priority.
-func (p Priority) String() string {
-	switch p {
-	case High:
-		return "High"
-	case Medium:
-		return "Medium"
-	case Low:
-		return "Low"
-	default:
-		return "Unknown"
+// Tag labels a task, for GORM
+type Tag struct {
+	gorm.Model
+	TaskID uint   `gorm:"not null;uniqueIndex:idx_task_tag" json:"task_id"`
+	Name   string `gorm:"type:varchar(30);not null;uniqueIndex:idx_task_tag;index" json:"name"` // Lower case without spaces
+}
+
+// ChecklistItem is one step of the checklist of a task, for GORM
+type ChecklistItem struct {
+	gorm.Model
+	TaskID   uint   `gorm:"not null;index" json:"task_id"`
+	Position int    `gorm:"not null" json:"position"` // Order of the item in the checklist
+	Text     string `gorm:"not null" json:"text"`
+	Done     bool   `gorm:"not null;default:false" json:"done"`
+}
+
+// Comment is a message left on a task, for GORM
+type Comment struct {
+	gorm.Model
+	TaskID  uint   `gorm:"not null;index" json:"task_id"`
+	UserID  string `gorm:"not null" json:"user_id"` // Author of the comment
+	Content string `gorm:"type:text;not null" json:"content"`
+}
+
+// Watcher follows the comments of a task, for GORM
+type Watcher struct {
+	gorm.Model
+	TaskID uint   `gorm:"not null;uniqueIndex:idx_task_watcher" json:"task_id"`
+	UserID string `gorm:"not null;uniqueIndex:idx_task_watcher;index" json:"user_id"`
+}
+
+// TagNames lists the names of the tags of a task
+func (t Task) TagNames() []string {
+	names := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		names = append(names, tag.Name)
 	}
+	return names
 }
 
-// Task represents a task model for GORM
-type Task struct {
+// VisibleTo reports whether a member who is no manager may see a task: they wrote it,
+// execute it, or could claim it for one of their roles. Visible and VisibleCondition are its SQL forms.
+func (t Task) VisibleTo(userID string, roles []string) bool {
+	return t.UserID == userID || t.ExecutorID == userID || (t.ExecutorID == "" && slices.Contains(roles, t.RoleID))
+}
+
+// VisibleCondition is VisibleTo as an SQL condition on the tasks under a table name or alias.
+// It takes the member's ID twice, then their roles.
+func VisibleCondition(table string) string {
+	return fmt.Sprintf("(%[1]s.user_id = ? OR %[1]s.executor_id = ? OR (%[1]s.executor_id = '' AND %[1]s.role_id IN ?))", table)
+}
+
+// Visible limits a query of tasks to the ones VisibleTo a member, unless everything is visible to them
+func Visible(userID string, roles []string, everything bool) func(*gorm.DB) *gorm.DB {
+	if roles == nil {
+		roles = []string{}
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if everything {
+			return db
+		}
+		return db.Where(VisibleCondition("tasks"), userID, userID, roles)
+	}
+}
+
+// Reference renders how users refer to a task: BE-42 in a project with a key, #42 otherwise
+func (t Task) Reference() string {
+	if t.ProjectKey != "" && t.ProjectNumber > 0 {
+		return fmt.Sprintf("%s-%d", t.ProjectKey, t.ProjectNumber)
+	}
+	return "#" + strconv.Itoa(t.TaskIdInGuild)
+}
+
+// ParseReference splits a task reference typed by a user. "BE-42" gives the project key and
+// the number within the project, while "42" and "#42" give the guild number and an empty key.
+func ParseReference(reference string) (string, int, error) {
+	reference = strings.TrimPrefix(strings.TrimSpace(reference), "#")
+
+	key := ""
+	if index := strings.LastIndex(reference, "-"); index > 0 {
+		key = strings.ToUpper(reference[:index])
+		reference = reference[index+1:]
+	}
+
+	number, err := strconv.Atoi(reference)
+	if err != nil || number < 1 {
+		return "", 0, fmt.Errorf("invalid task reference %q", reference)
+	}
+	return key, number, nil
+}
+
+// RoleCursor remembers the last member picked for a role by the round-robin strategy
+type RoleCursor struct {
 	gorm.Model
-	TaskIdInGuild int      `gorm:"not null" json:"task_id_in_guild"` // Task ID within a guild
-	UserID        string   `gorm:"not null" json:"user_id"`
-	ExecutorID    string   `gorm:"not null" json:"executor_id"`
-	GuildID       string   `gorm:"not null;index" json:"guild_id"`                    // Indexed for grouping tasks by guild
-	Title         string   `gorm:"not null" json:"title"`                             // Title of the task
-	Priority      Priority `gorm:"type:varchar(20);default:'Medium'" json:"priority"` // Priority of the task (High, Medium, Low)
-	Description   string   `gorm:"type:text" json:"description"`                      // Task description
+	GuildID    string `gorm:"not null;uniqueIndex:idx_role_cursor" json:"guild_id"`
+	RoleID     string `gorm:"not null;uniqueIndex:idx_role_cursor" json:"role_id"`
+	LastUserID string `gorm:"not null" json:"last_user_id"`
 }
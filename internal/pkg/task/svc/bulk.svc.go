@@ -0,0 +1,159 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+// errDryRun rolls back the transaction of a bulk operation that is only previewed
+var errDryRun = errors.New("dry run")
+
+// ListFilter limits tasks to the ones named in a task list
+func ListFilter(list ent.TaskList) Filter {
+	conditions := []string{}
+	var args []interface{}
+	if len(list.Numbers) > 0 {
+		conditions = append(conditions, "task_id_in_guild IN ?")
+		args = append(args, list.Numbers)
+	}
+	for key, numbers := range list.Keyed {
+		conditions = append(conditions, "(project_key = ? AND project_number IN ?)")
+		args = append(args, key, numbers)
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
+
+// SelectTasks retrieves at most limit tasks of a guild matching the filters, in the order of their numbers.
+// With an author, only the tasks they created are selected.
+func (s *TaskService) SelectTasks(guildID, authorID string, limit int, filters ...Filter) ([]ent.Task, error) {
+	var tasks []ent.Task
+	query := s.db.GetDB().Preload("Tags").Where("guild_id = ?", guildID)
+	if authorID != "" {
+		query = query.Where("user_id = ?", authorID)
+	}
+	for _, filter := range filters {
+		query = query.Scopes(filter)
+	}
+	err := query.Order("task_id_in_guild ASC").Limit(limit).Find(&tasks).Error
+	return tasks, err
+}
+
+// BulkApply makes one change to many tasks of a guild in a single transaction and returns the tasks as they end up.
+// With an author, every task must have been created by them. A dry run rolls the changes back after making
+// them, so a preview fails for the same reasons as the real operation, such as a move the workflow forbids.
+func (s *TaskService) BulkApply(guildID, authorID string, ids []uint, change ent.BulkChange, dryRun bool) ([]ent.Task, error) {
+	var tasks []ent.Task
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		query := tx.Preload("Tags").Where("guild_id = ? AND id IN ?", guildID, ids)
+		if authorID != "" {
+			query = query.Where("user_id = ?", authorID)
+		}
+		if err := query.Order("task_id_in_guild ASC").Find(&tasks).Error; err != nil {
+			return err
+		}
+		if len(tasks) != len(ids) {
+			return fmt.Errorf("%d of the tasks were deleted or changed hands", len(ids)-len(tasks))
+		}
+
+		var err error
+		switch change.Action {
+		case ent.Close:
+			err = s.bulkClose(tx, guildID, tasks)
+		case ent.Reassign:
+			err = tx.Model(&ent.Task{}).Where("id IN ?", ids).Update("executor_id", change.ExecutorID).Error
+			for index := range tasks {
+				tasks[index].ExecutorID = change.ExecutorID
+			}
+		case ent.Reprioritize:
+			err = tx.Model(&ent.Task{}).Where("id IN ?", ids).Update("priority", change.Priority).Error
+			for index := range tasks {
+				tasks[index].Priority = change.Priority
+			}
+		case ent.Retag:
+			err = bulkRetag(tx, tasks, change.AddTags, change.RemoveTags)
+		case ent.Delete:
+			err = tx.Where("id IN ?", ids).Delete(&ent.Task{}).Error
+		default:
+			err = fmt.Errorf("unknown bulk action %s", change.Action)
+		}
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// bulkClose moves tasks to the terminal state of the workflow, as far as the workflow allows
+func (s *TaskService) bulkClose(tx *gorm.DB, guildID string, tasks []ent.Task) error {
+	workflow, err := s.workflowService.GetWorkflow(guildID)
+	if err != nil {
+		return err
+	}
+	terminal := workflow.Terminal()
+	now := time.Now()
+
+	for index, task := range tasks {
+		if task.Status == ent.Status(terminal.Name) {
+			continue
+		}
+		state, err := s.workflowService.Move(guildID, string(task.Status), terminal.Name)
+		if err != nil {
+			return fmt.Errorf("task %s: %v", task.Reference(), err)
+		}
+		err = tx.Model(&ent.Task{}).Where("id = ?", task.ID).Updates(map[string]interface{}{
+			"status":       state.Name,
+			"completed_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		tasks[index].Status = ent.Status(state.Name)
+		tasks[index].CompletedAt = &now
+	}
+	return nil
+}
+
+// bulkRetag adds and removes tags of tasks, keeping every task within the tag limit
+func bulkRetag(tx *gorm.DB, tasks []ent.Task, add, remove []string) error {
+	for index, task := range tasks {
+		if len(remove) > 0 {
+			err := tx.Unscoped().Where("task_id = ? AND name IN ?", task.ID, remove).Delete(&ent.Tag{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, name := range add {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ent.Tag{TaskID: task.ID, Name: name}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var tags []ent.Tag
+		if err := tx.Where("task_id = ?", task.ID).Order("name").Find(&tags).Error; err != nil {
+			return err
+		}
+		if len(tags) > 10 {
+			return fmt.Errorf("task %s would have more than 10 tags", task.Reference())
+		}
+		tasks[index].Tags = tags
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package svc
+
+import (
+	"sort"
+	"taskchord/internal/pkg/task/ent"
+)
+
+// FindDuplicates lists the open tasks of a guild resembling a new title and description, the most similar first.
+// Only tasks the user can see are compared, unless everything is set. A task scores the better of its title alone and its title and description together, so a copied
+// bug report is found whether or not it was described the same way.
+func (s *TaskService) FindDuplicates(guildID, userID string, roles []string, everything bool, title, description string, threshold float64, limit int) ([]ent.Duplicate, error) {
+	var tasks []ent.Task
+	err := s.db.GetDB().
+		Where("guild_id = ? AND completed_at IS NULL", guildID).
+		Scopes(ent.Visible(userID, roles, everything)).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	titleTrigrams := ent.Trigrams(title)
+	textTrigrams := ent.Trigrams(title + " " + description)
+
+	var duplicates []ent.Duplicate
+	for _, task := range tasks {
+		score := ent.Similarity(titleTrigrams, ent.Trigrams(task.Title))
+		if textScore := ent.Similarity(textTrigrams, ent.Trigrams(task.Title+" "+task.Description)); textScore > score {
+			score = textScore
+		}
+		if score >= threshold {
+			duplicates = append(duplicates, ent.Duplicate{Task: task, Score: score})
+		}
+	}
+
+	sort.SliceStable(duplicates, func(a, b int) bool {
+		return duplicates[a].Score > duplicates[b].Score
+	})
+	if len(duplicates) > limit {
+		duplicates = duplicates[:limit]
+	}
+	return duplicates, nil
+}
@@ -0,0 +1,176 @@
+package svc
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"regexp"
+	"strconv"
+	"strings"
+	"taskchord/internal/pkg/task/ent"
+	"time"
+)
+
+// Filter limits a query of tasks, such as one compiled from a user's task query
+type Filter func(*gorm.DB) *gorm.DB
+
+// mentionPattern matches user and role mentions as Discord sends them
+var mentionPattern = regexp.MustCompile(`^<@[&!]?(\d+)>$`)
+
+// CompileQuery turns a parsed task query into a filter. Users are referred to as @me, a mention
+// or an ID, and relative dates such as 7d are counted from today, the current date at midnight UTC.
+// Every value ends up as a parameter of the SQL, never as part of it.
+func (s *TaskService) CompileQuery(guildID, userID string, query ent.Query, today time.Time) (Filter, error) {
+	conditions := make([]string, 0, len(query.Clauses))
+	var args [][]interface{}
+
+	for _, clause := range query.Clauses {
+		condition, clauseArgs, err := s.compileClause(guildID, userID, clause, today)
+		if err != nil {
+			return nil, err
+		}
+		if clause.Negated {
+			condition = "NOT (" + condition + ")"
+		}
+		conditions = append(conditions, "("+condition+")")
+		args = append(args, clauseArgs)
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		for index, condition := range conditions {
+			db = db.Where(condition, args[index]...)
+		}
+		return db
+	}, nil
+}
+
+// compileClause turns one clause into a condition on the tasks table and its parameters
+func (s *TaskService) compileClause(guildID, userID string, clause ent.Clause, today time.Time) (string, []interface{}, error) {
+	value := clause.Value
+	none := strings.EqualFold(value, "none")
+
+	switch clause.Field {
+	case "":
+		pattern := "%" + escapeLike(strings.ToLower(value)) + "%"
+		return "LOWER(tasks.title) LIKE ? OR LOWER(tasks.description) LIKE ?", []interface{}{pattern, pattern}, nil
+	case "status":
+		switch strings.ToLower(value) {
+		case "open":
+			return "tasks.completed_at IS NULL", nil, nil
+		case "done", "closed":
+			return "tasks.completed_at IS NOT NULL", nil, nil
+		}
+		return "LOWER(tasks.status) = LOWER(?)", []interface{}{value}, nil
+	case "priority":
+		return s.compilePriority(guildID, clause)
+	case "assignee", "author":
+		column := "tasks.executor_id"
+		if clause.Field == "author" {
+			column = "tasks.user_id"
+		}
+		if none {
+			return column + " = ''", nil, nil
+		}
+		id, err := queryUser(userID, value)
+		if err != nil {
+			return "", nil, err
+		}
+		return column + " = ?", []interface{}{id}, nil
+	case "role":
+		if none {
+			return "tasks.role_id = ''", nil, nil
+		}
+		id, err := queryUser(userID, value)
+		if err != nil {
+			return "", nil, err
+		}
+		return "tasks.role_id = ?", []interface{}{id}, nil
+	case "due":
+		if none {
+			return "tasks.due_at IS NULL", nil, nil
+		}
+		if strings.EqualFold(value, "overdue") {
+			return "tasks.completed_at IS NULL AND tasks.due_at < ?", []interface{}{today}, nil
+		}
+		day, err := ent.ParseDay(value, today)
+		if err != nil {
+			return "", nil, err
+		}
+		comparison := string(clause.Comparison)
+		if clause.Comparison == ent.Equal {
+			comparison = "="
+		}
+		return "tasks.due_at " + comparison + " ?", []interface{}{day}, nil
+	case "tag":
+		tag := strings.Join(strings.Fields(strings.ToLower(strings.TrimPrefix(value, "#"))), "-")
+		return "EXISTS (SELECT 1 FROM tags WHERE tags.task_id = tasks.id AND tags.deleted_at IS NULL AND tags.name = ?)", []interface{}{tag}, nil
+	case "project":
+		if none {
+			return "tasks.project_id IS NULL", nil, nil
+		}
+		return "tasks.project_id IN (SELECT id FROM projects WHERE guild_id = ? AND deleted_at IS NULL AND (LOWER(name) = LOWER(?) OR LOWER(key) = LOWER(?)))",
+			[]interface{}{guildID, value, value}, nil
+	case "milestone":
+		if none {
+			return "tasks.milestone_id IS NULL", nil, nil
+		}
+		return "tasks.milestone_id IN (SELECT id FROM milestones WHERE guild_id = ? AND deleted_at IS NULL AND LOWER(name) = LOWER(?))",
+			[]interface{}{guildID, value}, nil
+	}
+	return "", nil, fmt.Errorf("unknown field %s", clause.Field)
+}
+
+// compilePriority matches priorities by name, or by their place on the guild's scale when compared.
+// Higher priorities are the more urgent ones, so priority:>=medium also matches high.
+func (s *TaskService) compilePriority(guildID string, clause ent.Clause) (string, []interface{}, error) {
+	scale, err := s.priorityService.GetScale(guildID)
+	if err != nil {
+		return "", nil, err
+	}
+	level, ok := scale.Find(clause.Value)
+	if !ok {
+		return "", nil, fmt.Errorf("priority %s is not on the scale of this server", clause.Value)
+	}
+
+	names := []string{}
+	for _, other := range scale {
+		matches := false
+		switch clause.Comparison {
+		case ent.Equal:
+			matches = other.Position == level.Position
+		case ent.Greater:
+			matches = other.Position < level.Position
+		case ent.GreaterEqual:
+			matches = other.Position <= level.Position
+		case ent.Less:
+			matches = other.Position > level.Position
+		case ent.LessEqual:
+			matches = other.Position >= level.Position
+		}
+		if matches {
+			names = append(names, other.Name)
+		}
+	}
+	if len(names) == 0 {
+		return "FALSE", nil, nil
+	}
+	return "tasks.priority IN ?", []interface{}{names}, nil
+}
+
+// queryUser resolves @me, a mention or an ID to the ID of a user or role
+func queryUser(userID, value string) (string, error) {
+	if strings.EqualFold(value, "@me") || strings.EqualFold(value, "me") {
+		return userID, nil
+	}
+	if match := mentionPattern.FindStringSubmatch(value); match != nil {
+		return match[1], nil
+	}
+	if _, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return value, nil
+	}
+	return "", fmt.Errorf("%s is not @me, a mention or an ID", value)
+}
+
+// escapeLike keeps the wildcards of LIKE from being read in words typed by users
+func escapeLike(word string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(word)
+}
@@ -5,77 +5,317 @@ import (
 	"fmt"
 	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math/rand"
+	"slices"
+	"sort"
+	fieldEnt "taskchord/internal/pkg/field/ent"
+	guildEnt "taskchord/internal/pkg/guild/ent"
+	prioritySvc "taskchord/internal/pkg/priority/svc"
+	projectEnt "taskchord/internal/pkg/project/ent"
 	"taskchord/internal/pkg/task/ent"
+	workflowSvc "taskchord/internal/pkg/workflow/svc"
+	"time"
 )
 
 type TaskService struct {
-	db gossiper.Database
+	db              gossiper.Database
+	workflowService *workflowSvc.WorkflowService
+	priorityService *prioritySvc.PriorityService
 }
 
 // NewTaskService initializes a new task service
-func NewTaskService(db gossiper.Database) *TaskService {
-	return &TaskService{db: db}
+func NewTaskService(db gossiper.Database, workflowService *workflowSvc.WorkflowService, priorityService *prioritySvc.PriorityService) *TaskService {
+	return &TaskService{db: db, workflowService: workflowService, priorityService: priorityService}
+}
+
+// ByReference limits a query to the task of a guild that a user referred to, either by its
+// project key such as BE-42 or by its number in the guild such as 42
+func ByReference(guildID, reference string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		key, number, err := ent.ParseReference(reference)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		if key != "" {
+			return db.Where("guild_id = ? AND project_key = ? AND project_number = ?", guildID, key, number)
+		}
+		return db.Where("guild_id = ? AND task_id_in_guild = ?", guildID, number)
+	}
 }
 
 // CreateTask adds a task to the database
-func (s *TaskService) CreateTask(guildID, userID, title, description, priority string, executorID string) (int, error) {
-	var maxTaskIdInGuild int
+func (s *TaskService) CreateTask(guildID, userID, title, description, priority string, executorID string, dueAt *time.Time, estimate int, milestoneID, projectID *uint, tags, checklist []string) (ent.Task, error) {
+	return s.createTask(guildID, userID, title, description, priority, "", dueAt, estimate, milestoneID, projectID, tags, checklist, func(tx *gorm.DB) (string, error) {
+		return executorID, nil
+	})
+}
+
+// CreateTaskForRole adds a task assigned to a role, picking one of its members with the given strategy.
+// The executor of the returned task is empty if the task was left in the role queue.
+func (s *TaskService) CreateTaskForRole(guildID, userID, title, description, priority, roleID string, dueAt *time.Time, estimate int, milestoneID, projectID *uint, tags, checklist []string, strategy guildEnt.AssignStrategy, members []string) (ent.Task, error) {
+	return s.createTask(guildID, userID, title, description, priority, roleID, dueAt, estimate, milestoneID, projectID, tags, checklist, func(tx *gorm.DB) (string, error) {
+		return s.pickExecutor(tx, guildID, roleID, strategy, members)
+	})
+}
+
+// createTask stores a new task with its tags and checklist, resolving its executor inside the same transaction
+func (s *TaskService) createTask(guildID, userID, title, description, priority, roleID string, dueAt *time.Time, estimate int, milestoneID, projectID *uint, tags, checklist []string, resolveExecutor func(tx *gorm.DB) (string, error)) (ent.Task, error) {
+	var task ent.Task
 
 	// Start a transaction to ensure atomicity
 	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
-		// Find the highest TaskIdInGuild for the guild
-		err := tx.Model(&ent.Task{}).
-			Where("guild_id = ?", guildID).
-			Select("COALESCE(MAX(task_id_in_guild), 0)").
-			Scan(&maxTaskIdInGuild).Error
+		newTaskIdInGuild, err := nextTaskIdInGuild(tx, guildID)
+		if err != nil {
+			return err
+		}
+
+		// Tasks of a project also get the next number of the project and its key
+		projectKey, projectNumber, err := nextProjectNumber(tx, projectID)
 		if err != nil {
 			return err
 		}
 
-		// Increment TaskIdInGuild for the new task
-		newTaskIdInGuild := maxTaskIdInGuild + 1
+		executorID, err := resolveExecutor(tx)
+		if err != nil {
+			return err
+		}
+
+		// New tasks start in the first state of the guild's workflow
+		initial, err := s.workflowService.Initial(guildID)
+		if err != nil {
+			return err
+		}
 
 		// Create the new task with the incremented TaskIdInGuild
-		task := ent.Task{
+		task = ent.Task{
 			TaskIdInGuild: newTaskIdInGuild,
 			GuildID:       guildID,
 			UserID:        userID,
 			ExecutorID:    executorID,
+			RoleID:        roleID,
 			Title:         title,
 			Description:   description,
 			Priority:      ent.Priority(priority),
+			Status:        ent.Status(initial.Name),
+			DueAt:         dueAt,
+			Estimate:      estimate,
+			MilestoneID:   milestoneID,
+			ProjectID:     projectID,
+			ProjectKey:    projectKey,
+			ProjectNumber: projectNumber,
+			Tags:          makeTags(tags),
 		}
-
-		// Save the new task
-		if err := tx.Create(&task).Error; err != nil {
-			return err
+		for position, text := range checklist {
+			task.Checklist = append(task.Checklist, ent.ChecklistItem{Position: position, Text: text})
 		}
 
-		// Ensure the task is created and its ID is available before we return
+		// Save the new task along with its tags and checklist
+		return tx.Create(&task).Error
+	})
+
+	if err != nil {
+		return ent.Task{}, err
+	}
+
+	return task, nil
+}
+
+// ImportTasks stores tasks brought in from another tool, with their tags, checklists, comments, watchers
+// and custom field values, in one transaction. Every task gets the next number of the guild and of its project, whatever it had before.
+func (s *TaskService) ImportTasks(guildID string, tasks []ent.Task, comments [][]ent.Comment, watchers [][]string, values [][]fieldEnt.Value) ([]ent.Task, error) {
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		for index := range tasks {
+			task := &tasks[index]
+			number, err := nextTaskIdInGuild(tx, guildID)
+			if err != nil {
+				return err
+			}
+			task.GuildID = guildID
+			task.TaskIdInGuild = number
+			task.ProjectKey, task.ProjectNumber, err = nextProjectNumber(tx, task.ProjectID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(task).Error; err != nil {
+				return err
+			}
+
+			if index < len(comments) {
+				for _, comment := range comments[index] {
+					comment.TaskID = task.ID
+					if err := tx.Create(&comment).Error; err != nil {
+						return err
+					}
+				}
+			}
+			if index < len(watchers) {
+				for _, userID := range watchers[index] {
+					if err := watch(tx, task.ID, userID); err != nil {
+						return err
+					}
+				}
+			}
+			if index < len(values) {
+				for _, value := range values[index] {
+					value.TaskID = task.ID
+					if err := tx.Create(&value).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
 		return nil
 	})
 
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// nextTaskIdInGuild finds the number the next task of a guild gets
+func nextTaskIdInGuild(tx *gorm.DB, guildID string) (int, error) {
+	// Deleted and merged tasks keep their numbers, so they are counted as well
+	var maxTaskIdInGuild int
+	err := tx.Unscoped().Model(&ent.Task{}).
+		Where("guild_id = ?", guildID).
+		Select("COALESCE(MAX(task_id_in_guild), 0)").
+		Scan(&maxTaskIdInGuild).Error
+	return maxTaskIdInGuild + 1, err
+}
+
+// nextProjectNumber finds the key of a project and the number its next task gets.
+// Tasks outside projects get an empty key and number 0.
+func nextProjectNumber(tx *gorm.DB, projectID *uint) (string, int, error) {
+	if projectID == nil {
+		return "", 0, nil
+	}
+
+	var project projectEnt.Project
+	if err := tx.First(&project, *projectID).Error; err != nil {
+		return "", 0, err
+	}
+	var projectNumber int
+	err := tx.Unscoped().Model(&ent.Task{}).
+		Where("project_id = ?", *projectID).
+		Select("COALESCE(MAX(project_number), 0)").
+		Scan(&projectNumber).Error
+	return project.Key, projectNumber + 1, err
+}
+
+// pickExecutor selects the member of a role who receives a new task.
+// An empty result means the task stays unclaimed in the role queue.
+func (s *TaskService) pickExecutor(tx *gorm.DB, guildID, roleID string, strategy guildEnt.AssignStrategy, members []string) (string, error) {
+	if len(members) == 0 {
+		return "", nil
+	}
+
+	switch strategy {
+	case guildEnt.Queue:
+		return "", nil
+	case guildEnt.Random:
+		return members[rand.Intn(len(members))], nil
+	case guildEnt.LeastLoaded:
+		var loads []struct {
+			ExecutorID string
+			Count      int
+		}
+		err := tx.Model(&ent.Task{}).
+			Select("executor_id, COUNT(*) AS count").
+			Where("guild_id = ? AND executor_id IN ? AND completed_at IS NULL", guildID, members).
+			Group("executor_id").
+			Scan(&loads).Error
+		if err != nil {
+			return "", err
+		}
+
+		counts := make(map[string]int, len(loads))
+		for _, load := range loads {
+			counts[load.ExecutorID] = load.Count
+		}
+
+		// Members are checked in order, so ties go to the first of them
+		picked := members[0]
+		for _, member := range members[1:] {
+			if counts[member] < counts[picked] {
+				picked = member
+			}
+		}
+		return picked, nil
+	default: // Round-robin
+		sorted := append([]string(nil), members...)
+		sort.Strings(sorted)
+
+		cursor := ent.RoleCursor{GuildID: guildID, RoleID: roleID}
+		err := tx.Where("guild_id = ? AND role_id = ?", guildID, roleID).FirstOrInit(&cursor).Error
+		if err != nil {
+			return "", err
+		}
+
+		// Continue after the last picked member; if they left the role, start over from the next ID in order
+		next := sort.SearchStrings(sorted, cursor.LastUserID)
+		if next < len(sorted) && sorted[next] == cursor.LastUserID {
+			next++
+		}
+		picked := sorted[next%len(sorted)]
+
+		cursor.LastUserID = picked
+		if err := tx.Save(&cursor).Error; err != nil {
+			return "", err
+		}
+		return picked, nil
 	}
+}
+
+// ClaimTask assigns an unclaimed role task to a member of that role
+func (s *TaskService) ClaimTask(guildID, userID, id string, roles []string) (ent.Task, error) {
+	var task ent.Task
+
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Scopes(ByReference(guildID, id)).First(&task).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("task with ID %s does not exist", id)
+			}
+			return err
+		}
+
+		if task.RoleID == "" || task.ExecutorID != "" {
+			return fmt.Errorf("task with ID %s is not waiting in a role queue", id)
+		}
+		if !slices.Contains(roles, task.RoleID) {
+			return fmt.Errorf("user %s does not have the role of task %s", userID, id)
+		}
+
+		// Guard against two members claiming the same task at once
+		result := tx.Model(&ent.Task{}).
+			Where("id = ? AND executor_id = ''", task.ID).
+			Update("executor_id", userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("task with ID %s was already claimed", id)
+		}
+		task.ExecutorID = userID
+		return nil
+	})
 
-	// Now retrieve the TaskIdInGuild from the newly created task
-	var createdTask ent.Task
-	err = s.db.GetDB().Where("guild_id = ? AND user_id = ? AND title = ?", guildID, userID, title).First(&createdTask).Error
 	if err != nil {
-		return 0, err
+		return ent.Task{}, err
 	}
 
-	// Return the ID of the newly created task
-	return createdTask.TaskIdInGuild, nil
+	return task, nil
 }
 
-func (s *TaskService) UpdateTask(guildID, userID, title, description, priority, executorID, id string) (int, error) {
+func (s *TaskService) UpdateTask(guildID, userID, title, description, priority, executorID, status, id string, dueAt *time.Time, estimate int, milestoneID *uint, tags []string) (ent.Task, error) {
 	// Start a transaction to ensure atomicity
+	var task ent.Task
 	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
-		// Fetch the existing task by guild ID, user ID, and task ID
-		var task ent.Task
-		err := tx.Where("guild_id = ? AND user_id = ? AND task_id_in_guild = ?", guildID, userID, id).First(&task).Error
+		// Fetch the existing task by guild ID, user ID, and task reference
+		err := tx.Scopes(ByReference(guildID, id)).Where("user_id = ?", userID).First(&task).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return fmt.Errorf("task with ID %s does not exist", id)
@@ -96,6 +336,41 @@ func (s *TaskService) UpdateTask(guildID, userID, title, description, priority,
 		if executorID != "" { // Update executor if provided
 			task.ExecutorID = executorID
 		}
+		if dueAt != nil {
+			task.DueAt = dueAt
+		}
+		if estimate != 0 {
+			task.Estimate = estimate
+		}
+		if tags != nil { // An empty list removes every tag
+			if err := tx.Unscoped().Where("task_id = ?", task.ID).Delete(&ent.Tag{}).Error; err != nil {
+				return err
+			}
+			task.Tags = makeTags(tags)
+		}
+		if milestoneID != nil { // Zero takes the task out of its milestone
+			task.MilestoneID = milestoneID
+			if *milestoneID == 0 {
+				task.MilestoneID = nil
+			}
+		}
+		if status != "" {
+			// The workflow decides which moves are allowed
+			state, err := s.workflowService.Move(guildID, string(task.Status), status)
+			if err != nil {
+				return err
+			}
+
+			// Track when the task was finished; leaving the terminal state clears it
+			if ent.Status(state.Name) != task.Status {
+				task.Status = ent.Status(state.Name)
+				task.CompletedAt = nil
+				if state.Terminal {
+					now := time.Now()
+					task.CompletedAt = &now
+				}
+			}
+		}
 
 		// Save the changes
 		if err := tx.Save(&task).Error; err != nil {
@@ -106,42 +381,421 @@ func (s *TaskService) UpdateTask(guildID, userID, title, description, priority,
 	})
 
 	if err != nil {
-		return 0, err
-	}
-
-	// Retrieve the updated task to confirm the changes and get the ID
-	var updatedTask ent.Task
-	err = s.db.GetDB().Where("guild_id = ? AND user_id = ? AND task_id_in_guild = ?", guildID, userID, id).First(&updatedTask).Error
-	if err != nil {
-		return 0, err
+		return ent.Task{}, err
 	}
 
-	return updatedTask.TaskIdInGuild, nil
+	return task, nil
 }
 
-// GetTasksByUserID retrieves tasks for a specific user from the database
-func (s *TaskService) GetTasksByUserID(guildID string, userID string, id string) ([]ent.Task, error) {
+// GetTasksByUserID retrieves tasks for a specific user from the database,
+// including unclaimed tasks queued for any of the given roles, optionally in one status and matching filters
+func (s *TaskService) GetTasksByUserID(guildID string, userID string, id string, roles []string, projectID *uint, status string, filters ...Filter) ([]ent.Task, error) {
 	var tasks []ent.Task
 	var err error
 
 	if id != "" { // If a specific task ID is provided
 		err = s.db.GetDB().
-			Where("(user_id = ? OR executor_id = ?) AND guild_id = ? AND task_id_in_guild = ?", userID, userID, guildID, id).
-			Find(&tasks).Error
-	} else { // Fetch all tasks for the user (as author or executor) in the guild
-		err = s.db.GetDB().
-			Where("(user_id = ? OR executor_id = ?) AND guild_id = ?", userID, userID, guildID).
-			Order("task_id_in_guild ASC").
+			Preload("Tags").
+			Preload("Checklist", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+			Scopes(ent.Visible(userID, roles, false), ByReference(guildID, id)).
 			Find(&tasks).Error
+	} else { // Fetch all tasks for the user (as author, executor or role member) in the guild
+		query := s.db.GetDB().
+			Preload("Tags").
+			Preload("Checklist", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+			Scopes(ent.Visible(userID, roles, false)).
+			Where("guild_id = ?", guildID)
+		if projectID != nil {
+			query = query.Where("project_id = ?", *projectID)
+		}
+		if status != "" {
+			query = query.Where("LOWER(status) = LOWER(?)", status)
+		}
+		for _, filter := range filters {
+			query = query.Scopes(filter)
+		}
+		err = query.Order("task_id_in_guild ASC").Find(&tasks).Error
+	}
+
+	return tasks, err
+}
+
+// GetTask retrieves a task of a guild by its reference
+func (s *TaskService) GetTask(guildID, id string) (ent.Task, error) {
+	var task ent.Task
+	err := s.db.GetDB().
+		Preload("Tags").
+		Preload("Checklist", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Scopes(ByReference(guildID, id)).
+		First(&task).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return task, s.missingTask(guildID, id)
+	}
+	return task, err
+}
+
+// missingTask explains why a task cannot be found, pointing to the task it was merged into if any
+func (s *TaskService) missingTask(guildID, id string) error {
+	if target, err := s.GetMergeTarget(guildID, id); err == nil {
+		return fmt.Errorf("task with ID %s was merged into %s", id, target.Reference())
+	}
+	return fmt.Errorf("task with ID %s does not exist", id)
+}
+
+// GetMergeTarget retrieves the task that a merged task of a guild was folded into
+func (s *TaskService) GetMergeTarget(guildID, id string) (ent.Task, error) {
+	var merged, target ent.Task
+	err := s.db.GetDB().Unscoped().
+		Scopes(ByReference(guildID, id)).
+		Where("merged_into_id IS NOT NULL").
+		First(&merged).Error
+	if err != nil {
+		return target, err
+	}
+	err = s.db.GetDB().First(&target, *merged.MergedIntoID).Error
+	return target, err
+}
+
+// CloneTask copies a task into a new task of the given author with the next numbers, custom field values included.
+// The copy starts in the first state of the workflow with its checklist unchecked.
+func (s *TaskService) CloneTask(guildID, userID, id string, includeChecklist, includeComments bool) (ent.Task, ent.Task, error) {
+	source, err := s.GetTask(guildID, id)
+	if err != nil {
+		return ent.Task{}, ent.Task{}, err
+	}
+
+	var clone ent.Task
+	err = s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		taskIdInGuild, err := nextTaskIdInGuild(tx, guildID)
+		if err != nil {
+			return err
+		}
+		projectKey, projectNumber, err := nextProjectNumber(tx, source.ProjectID)
+		if err != nil {
+			return err
+		}
+		initial, err := s.workflowService.Initial(guildID)
+		if err != nil {
+			return err
+		}
+
+		clone = ent.Task{
+			TaskIdInGuild: taskIdInGuild,
+			GuildID:       guildID,
+			UserID:        userID,
+			ExecutorID:    source.ExecutorID,
+			RoleID:        source.RoleID,
+			Title:         source.Title,
+			Description:   source.Description,
+			Priority:      source.Priority,
+			Status:        ent.Status(initial.Name),
+			DueAt:         source.DueAt,
+			Estimate:      source.Estimate,
+			MilestoneID:   source.MilestoneID,
+			ProjectID:     source.ProjectID,
+			ProjectKey:    projectKey,
+			ProjectNumber: projectNumber,
+			Tags:          makeTags(source.TagNames()),
+		}
+		if includeChecklist {
+			for _, item := range source.Checklist {
+				clone.Checklist = append(clone.Checklist, ent.ChecklistItem{Position: item.Position, Text: item.Text})
+			}
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return err
+		}
+		if err := copyFieldValues(tx, source.ID, clone.ID); err != nil {
+			return err
+		}
+
+		if !includeComments {
+			return nil
+		}
+		var comments []ent.Comment
+		if err := tx.Where("task_id = ?", source.ID).Order("created_at").Find(&comments).Error; err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			copied := ent.Comment{TaskID: clone.ID, UserID: comment.UserID, Content: comment.Content}
+			copied.CreatedAt = comment.CreatedAt
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return ent.Task{}, ent.Task{}, err
+	}
+
+	return source, clone, nil
+}
+
+// MergeTasks folds a duplicate task into another one. The target gets the description, tags,
+// checklist, comments, watchers and missing custom field values of the source, whose author and executor start watching it.
+// The source is deleted but remembers the target, so references to it can point there.
+func (s *TaskService) MergeTasks(guildID, sourceID, targetID string) (ent.Task, ent.Task, error) {
+	source, err := s.GetTask(guildID, sourceID)
+	if err != nil {
+		return ent.Task{}, ent.Task{}, err
+	}
+	target, err := s.GetTask(guildID, targetID)
+	if err != nil {
+		return ent.Task{}, ent.Task{}, err
+	}
+	if source.ID == target.ID {
+		return ent.Task{}, ent.Task{}, fmt.Errorf("task with ID %s cannot be merged into itself", sourceID)
+	}
+
+	err = s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if source.Description != "" && source.Description != target.Description {
+			description := fmt.Sprintf("%s\n\nMerged from %s %s:\n%s", target.Description, source.Reference(), source.Title, source.Description)
+			if err := tx.Model(&target).Update("description", description).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, name := range source.TagNames() {
+			if slices.Contains(target.TagNames(), name) {
+				continue
+			}
+			if err := tx.Create(&ent.Tag{TaskID: target.ID, Name: name}).Error; err != nil {
+				return err
+			}
+		}
+
+		// The checklist of the source continues after the one of the target
+		position := len(target.Checklist)
+		if position > 0 {
+			position = target.Checklist[len(target.Checklist)-1].Position + 1
+		}
+		for _, item := range source.Checklist {
+			err := tx.Model(&item).Updates(map[string]interface{}{"task_id": target.ID, "position": position}).Error
+			if err != nil {
+				return err
+			}
+			position++
+		}
+
+		if err := tx.Model(&ent.Comment{}).Where("task_id = ?", source.ID).Update("task_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		var watchers []string
+		if err := tx.Model(&ent.Watcher{}).Where("task_id = ?", source.ID).Pluck("user_id", &watchers).Error; err != nil {
+			return err
+		}
+		watchers = append(watchers, source.UserID, source.ExecutorID)
+		for _, userID := range watchers {
+			if userID == "" || userID == target.UserID || userID == target.ExecutorID {
+				continue
+			}
+			if err := watch(tx, target.ID, userID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("task_id = ?", source.ID).Delete(&ent.Watcher{}).Error; err != nil {
+			return err
+		}
+
+		if err := copyFieldValues(tx, source.ID, target.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&source).Update("merged_into_id", target.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+
+	if err != nil {
+		return ent.Task{}, ent.Task{}, err
+	}
+
+	target, err = s.GetTask(guildID, targetID)
+	return source, target, err
+}
+
+// MoveTask moves a task to another project, or out of its project if projectID is nil.
+// The task gets the next number of the new project, so its key changes while its guild number stays.
+func (s *TaskService) MoveTask(guildID, id string, projectID *uint) (ent.Task, error) {
+	var task ent.Task
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Scopes(ByReference(guildID, id)).First(&task).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("task with ID %s does not exist", id)
+			}
+			return err
+		}
+
+		if (task.ProjectID == nil && projectID == nil) || (task.ProjectID != nil && projectID != nil && *task.ProjectID == *projectID) {
+			return fmt.Errorf("task with ID %s is already in that project", id)
+		}
+
+		task.ProjectID = projectID
+		task.ProjectKey, task.ProjectNumber, err = nextProjectNumber(tx, projectID)
+		if err != nil {
+			return err
+		}
+		return tx.Select("project_id", "project_key", "project_number").Save(&task).Error
+	})
+
+	if err != nil {
+		return ent.Task{}, err
 	}
 
+	return task, nil
+}
+
+// AddComment leaves a comment on a task
+func (s *TaskService) AddComment(taskID uint, userID, content string) (ent.Comment, error) {
+	comment := ent.Comment{TaskID: taskID, UserID: userID, Content: content}
+	err := s.db.GetDB().Create(&comment).Error
+	return comment, err
+}
+
+// GetComments retrieves the comments of a task, the oldest first
+func (s *TaskService) GetComments(taskID uint) ([]ent.Comment, error) {
+	var comments []ent.Comment
+	err := s.db.GetDB().Where("task_id = ?", taskID).Order("created_at ASC").Find(&comments).Error
+	return comments, err
+}
+
+// ToggleWatch starts or stops a user watching a task and reports whether they watch it now
+func (s *TaskService) ToggleWatch(taskID uint, userID string) (bool, error) {
+	result := s.db.GetDB().Unscoped().Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&ent.Watcher{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return false, nil
+	}
+	return true, watch(s.db.GetDB(), taskID, userID)
+}
+
+// WatchTask makes a user watch a task, leaving it as is when they already do
+func (s *TaskService) WatchTask(taskID uint, userID string) error {
+	return watch(s.db.GetDB(), taskID, userID)
+}
+
+// GetWatchers lists the users watching a task
+func (s *TaskService) GetWatchers(taskID uint) ([]string, error) {
+	var watchers []string
+	err := s.db.GetDB().Model(&ent.Watcher{}).Where("task_id = ?", taskID).Order("created_at ASC").Pluck("user_id", &watchers).Error
+	return watchers, err
+}
+
+// copyFieldValues gives a task the custom field values of another task for the fields it has no value for
+func copyFieldValues(tx *gorm.DB, fromTaskID, toTaskID uint) error {
+	var values []fieldEnt.Value
+	err := tx.Where("task_id = ?", fromTaskID).
+		Where("definition_id NOT IN (?)", tx.Model(&fieldEnt.Value{}).Select("definition_id").Where("task_id = ?", toTaskID)).
+		Find(&values).Error
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := tx.Create(&fieldEnt.Value{DefinitionID: value.DefinitionID, TaskID: toTaskID, Value: value.Value}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watch makes a user watch a task unless they already do
+func watch(tx *gorm.DB, taskID uint, userID string) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ent.Watcher{TaskID: taskID, UserID: userID}).Error
+}
+
+// AddChecklistItem appends an item to the checklist of a task
+func (s *TaskService) AddChecklistItem(taskID uint, text string) (ent.ChecklistItem, error) {
+	item := ent.ChecklistItem{TaskID: taskID, Text: text}
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&ent.ChecklistItem{}).
+			Where("task_id = ?", taskID).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Scan(&item.Position).Error
+		if err != nil {
+			return err
+		}
+		if item.Position >= 25 {
+			return fmt.Errorf("a checklist can have at most 25 items")
+		}
+		return tx.Create(&item).Error
+	})
+	return item, err
+}
+
+// ToggleChecklistItem checks or unchecks the item at the given place of a checklist, counting from 1
+func (s *TaskService) ToggleChecklistItem(taskID uint, number int) (ent.ChecklistItem, error) {
+	item, err := s.checklistItem(taskID, number)
+	if err != nil {
+		return item, err
+	}
+	item.Done = !item.Done
+	err = s.db.GetDB().Model(&item).Update("done", item.Done).Error
+	return item, err
+}
+
+// RemoveChecklistItem deletes the item at the given place of a checklist, counting from 1
+func (s *TaskService) RemoveChecklistItem(taskID uint, number int) (ent.ChecklistItem, error) {
+	item, err := s.checklistItem(taskID, number)
+	if err != nil {
+		return item, err
+	}
+	err = s.db.GetDB().Unscoped().Delete(&item).Error
+	return item, err
+}
+
+// checklistItem finds the item at the given place of a checklist, counting from 1
+func (s *TaskService) checklistItem(taskID uint, number int) (ent.ChecklistItem, error) {
+	var item ent.ChecklistItem
+	err := s.db.GetDB().
+		Where("task_id = ?", taskID).
+		Order("position").
+		Offset(number - 1).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return item, fmt.Errorf("checklist item %d does not exist", number)
+	}
+	return item, err
+}
+
+// makeTags turns tag names into tags of a new task
+func makeTags(names []string) []ent.Tag {
+	tags := make([]ent.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, ent.Tag{Name: name})
+	}
+	return tags
+}
+
+// GetBoard retrieves the tasks of a guild, or of one of its projects, that belong on a board:
+// every open task and the tasks that reached the terminal state since the given time, matching the filters.
+// Unless everything is visible, only the tasks a member authored, executes or could claim for one of their roles are included.
+func (s *TaskService) GetBoard(guildID, userID string, roles []string, everything bool, projectID *uint, doneSince time.Time, filters ...Filter) ([]ent.Task, error) {
+	var tasks []ent.Task
+	query := s.db.GetDB().
+		Scopes(ent.Visible(userID, roles, everything)).
+		Where("guild_id = ?", guildID).
+		Where("completed_at IS NULL OR completed_at >= ?", doneSince)
+	if projectID != nil {
+		query = query.Where("project_id = ?", *projectID)
+	}
+	for _, filter := range filters {
+		query = query.Scopes(filter)
+	}
+	err := query.Order("due_at ASC NULLS LAST, task_id_in_guild ASC").Find(&tasks).Error
 	return tasks, err
 }
 
 func (s *TaskService) DeleteTask(guildID string, userID string, id string) (string, error) {
-	// Find the task by guildID, userID, and task ID (taskIdInGuild)
+	// Find the task by guildID, userID, and task reference
 	var task ent.Task
-	err := s.db.GetDB().Where("guild_id = ? AND user_id = ? AND task_id_in_guild = ?", guildID, userID, id).First(&task).Error
+	err := s.db.GetDB().Scopes(ByReference(guildID, id)).Where("user_id = ?", userID).First(&task).Error
 	if err != nil {
 		// Handle case where task is not found
 		return "", fmt.Errorf("task not found: %v", err)
@@ -153,6 +807,6 @@ func (s *TaskService) DeleteTask(guildID string, userID string, id string) (stri
 		return "", fmt.Errorf("error deleting task: %v", err)
 	}
 
-	// Return the ID of the deleted task
-	return id, nil
+	// Return the reference of the deleted task
+	return task.Reference(), nil
 }
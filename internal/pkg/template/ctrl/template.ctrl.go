@@ -0,0 +1,87 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"taskchord/internal/pkg/template/ent"
+	"taskchord/internal/pkg/template/svc"
+)
+
+type TemplateController struct {
+	templateService *svc.TemplateService
+}
+
+// NewTemplateController creates a new template controller
+func NewTemplateController(templateService *svc.TemplateService) *TemplateController {
+	return &TemplateController{templateService: templateService}
+}
+
+// SaveTemplate stores the shape of an existing task as a template under the given name
+func (c *TemplateController) SaveTemplate(guildID, userID, name string, task taskEnt.Task) (ent.Template, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 50 {
+		log.Println("Controller error: Template name must have between 1 and 50 characters")
+		return ent.Template{}, fmt.Errorf("template name must have between 1 and 50 characters")
+	}
+
+	var checklist []string
+	for _, item := range task.Checklist {
+		checklist = append(checklist, item.Text)
+	}
+
+	template := ent.Template{
+		GuildID:     guildID,
+		Name:        name,
+		UserID:      userID,
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    string(task.Priority),
+		Tags:        strings.Join(task.TagNames(), ","),
+		Checklist:   strings.Join(checklist, "\n"),
+	}
+
+	// Role tasks pick a fresh executor every time, and self-assigned tasks go to whoever uses the template
+	if task.RoleID != "" {
+		template.RoleID = task.RoleID
+	} else if task.ExecutorID != task.UserID {
+		template.ExecutorID = task.ExecutorID
+	}
+
+	template, err := c.templateService.SaveTemplate(template)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Template{}, err
+	}
+	return template, nil
+}
+
+// GetTemplate finds a template by name
+func (c *TemplateController) GetTemplate(guildID, name string) (ent.Template, error) {
+	template, err := c.templateService.GetTemplate(guildID, name)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Template{}, err
+	}
+	return template, nil
+}
+
+// GetTemplates lists the templates of a guild
+func (c *TemplateController) GetTemplates(guildID string) ([]ent.Template, error) {
+	templates, err := c.templateService.GetTemplates(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return templates, nil
+}
+
+// DeleteTemplate removes a template
+func (c *TemplateController) DeleteTemplate(guildID, name string) error {
+	if err := c.templateService.DeleteTemplate(guildID, name); err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
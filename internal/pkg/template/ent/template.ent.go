@@ -0,0 +1,53 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	"strings"
+)
+
+// Template describes the shape of a task that is created again and again, for GORM.
+// Its title, description and checklist may hold variables such as {date} or {user}.
+type Template struct {
+	gorm.Model
+	GuildID     string `gorm:"not null;uniqueIndex:idx_template_name" json:"guild_id"`
+	Name        string `gorm:"type:varchar(50);not null;uniqueIndex:idx_template_name" json:"name"`
+	UserID      string `gorm:"not null" json:"user_id"` // Member who saved the template
+	Title       string `gorm:"not null" json:"title"`
+	Description string `gorm:"type:text" json:"description"`
+	Priority    string `gorm:"type:varchar(20)" json:"priority"`
+	Tags        string `json:"tags"`                       // Comma separated tag names
+	Checklist   string `gorm:"type:text" json:"checklist"` // Checklist items, one per line
+	ExecutorID  string `json:"executor_id"`                // Default executor, empty if none
+	RoleID      string `json:"role_id"`                    // Default role to pick an executor from, empty if none
+}
+
+// TagNames lists the tags of the template
+func (t Template) TagNames() []string {
+	if t.Tags == "" {
+		return nil
+	}
+	return strings.Split(t.Tags, ",")
+}
+
+// ChecklistItems lists the checklist items of the template
+func (t Template) ChecklistItems() []string {
+	if t.Checklist == "" {
+		return nil
+	}
+	return strings.Split(t.Checklist, "\n")
+}
+
+// Expand fills in the variables of the title, description and checklist, such as {date} for the key date.
+// Unknown variables are left as they are.
+func (t Template) Expand(variables map[string]string) Template {
+	var pairs []string
+	for key, value := range variables {
+		pairs = append(pairs, "{"+key+"}", value)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	t.Title = replacer.Replace(t.Title)
+	t.Description = replacer.Replace(t.Description)
+	t.Checklist = replacer.Replace(t.Checklist)
+	return t
+}
@@ -0,0 +1,63 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"taskchord/internal/pkg/template/ent"
+)
+
+type TemplateService struct {
+	db gossiper.Database
+}
+
+// NewTemplateService initializes a new template service
+func NewTemplateService(db gossiper.Database) *TemplateService {
+	return &TemplateService{db: db}
+}
+
+// SaveTemplate stores a template, replacing the template of the same name
+func (s *TemplateService) SaveTemplate(template ent.Template) (ent.Template, error) {
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("guild_id = ? AND LOWER(name) = LOWER(?)", template.GuildID, template.Name).
+			Delete(&ent.Template{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&template).Error
+	})
+	return template, err
+}
+
+// GetTemplate finds a template of a guild by name, ignoring case
+func (s *TemplateService) GetTemplate(guildID, name string) (ent.Template, error) {
+	var template ent.Template
+	err := s.db.GetDB().Where("guild_id = ? AND LOWER(name) = LOWER(?)", guildID, name).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return template, fmt.Errorf("template %s does not exist", name)
+	}
+	return template, err
+}
+
+// GetTemplates lists the templates of a guild, by name
+func (s *TemplateService) GetTemplates(guildID string) ([]ent.Template, error) {
+	var templates []ent.Template
+	err := s.db.GetDB().Where("guild_id = ?", guildID).Order("name").Find(&templates).Error
+	return templates, err
+}
+
+// DeleteTemplate removes a template of a guild
+func (s *TemplateService) DeleteTemplate(guildID, name string) error {
+	result := s.db.GetDB().Unscoped().
+		Where("guild_id = ? AND LOWER(name) = LOWER(?)", guildID, name).
+		Delete(&ent.Template{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("template %s does not exist", name)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"taskchord/internal/pkg/track/ent"
+	"taskchord/internal/pkg/track/svc"
+	"time"
+)
+
+type TrackController struct {
+	trackService *svc.TrackService
+}
+
+// NewTrackController creates a new time tracking controller
+func NewTrackController(trackService *svc.TrackService) *TrackController {
+	return &TrackController{trackService: trackService}
+}
+
+// StartTimer starts tracking time on a task
+func (c *TrackController) StartTimer(guildID, userID, id string) (taskEnt.Task, error) {
+	if id == "" {
+		log.Println("Controller error: Task ID is required")
+		return taskEnt.Task{}, fmt.Errorf("task ID is required")
+	}
+
+	task, err := c.trackService.StartTimer(guildID, userID, id)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return taskEnt.Task{}, err
+	}
+	return task, nil
+}
+
+// StopTimer stops the running timer of a user
+func (c *TrackController) StopTimer(userID string) (taskEnt.Task, ent.Entry, error) {
+	task, entry, err := c.trackService.StopTimer(userID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return taskEnt.Task{}, ent.Entry{}, err
+	}
+	return task, entry, nil
+}
+
+// LogTime validates and records work on a task that was not timed
+func (c *TrackController) LogTime(guildID, userID, id string, duration time.Duration, endedAt time.Time) (taskEnt.Task, error) {
+	if id == "" {
+		log.Println("Controller error: Task ID is required")
+		return taskEnt.Task{}, fmt.Errorf("task ID is required")
+	}
+	if duration <= 0 || duration > 24*time.Hour {
+		log.Println("Controller error: Logged time must be between 1 second and 24 hours")
+		return taskEnt.Task{}, fmt.Errorf("logged time must be between 1 second and 24 hours")
+	}
+
+	task, err := c.trackService.LogTime(guildID, userID, id, duration, endedAt)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return taskEnt.Task{}, err
+	}
+	return task, nil
+}
+
+// StopIdleTimers stops every timer that has been running longer than the limit
+func (c *TrackController) StopIdleTimers(limit time.Duration) ([]ent.Entry, error) {
+	return c.trackService.StopIdleTimers(limit)
+}
+
+// GetSpentSeconds sums the tracked time of each of the given tasks
+func (c *TrackController) GetSpentSeconds(tasks []taskEnt.Task) (map[uint]int64, error) {
+	if len(tasks) == 0 {
+		return map[uint]int64{}, nil
+	}
+
+	taskIDs := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+	return c.trackService.GetSpentSeconds(taskIDs)
+}
+
+// GetTimesheet validates the period and retrieves the timesheet of a user
+func (c *TrackController) GetTimesheet(guildID, userID string, from, to time.Time, location *time.Location) (ent.Timesheet, error) {
+	if !from.Before(to) {
+		log.Println("Controller error: Timesheet period must end after it starts")
+		return ent.Timesheet{}, fmt.Errorf("timesheet period must end after it starts")
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		log.Println("Controller error: Timesheet period cannot be longer than a year")
+		return ent.Timesheet{}, fmt.Errorf("timesheet period cannot be longer than a year")
+	}
+
+	timesheet, err := c.trackService.GetTimesheet(guildID, userID, from, to, location)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Timesheet{}, err
+	}
+	return timesheet, nil
+}
@@ -0,0 +1,41 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Entry represents a work session on a task, either timed or logged by hand, for GORM
+type Entry struct {
+	gorm.Model
+	GuildID   string     `gorm:"not null;index" json:"guild_id"`
+	UserID    string     `gorm:"not null;uniqueIndex:idx_active_timer,where:ended_at IS NULL AND deleted_at IS NULL" json:"user_id"` // One running timer per user
+	TaskID    uint       `gorm:"not null;index" json:"task_id"`                                                                      // Primary key of the task
+	StartedAt time.Time  `gorm:"not null" json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`                // Nil while the timer is running
+	Seconds   int64      `gorm:"not null" json:"seconds"` // Length of the session once it ended
+}
+
+// TimesheetTask holds the time tracked on one task during one day
+type TimesheetTask struct {
+	TaskIdInGuild int
+	Reference     string // Such as BE-42 or #42
+	Title         string
+	Seconds       int64
+}
+
+// TimesheetDay holds the time tracked during one local day, per task
+type TimesheetDay struct {
+	Date    time.Time // Midnight of the day in the user's time zone
+	Tasks   []TimesheetTask
+	Seconds int64
+}
+
+// Timesheet holds the time a user tracked in a period, broken down per day and task
+type Timesheet struct {
+	UserID  string
+	From    time.Time
+	To      time.Time // Exclusive
+	Days    []TimesheetDay
+	Seconds int64
+}
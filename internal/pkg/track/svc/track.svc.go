@@ -0,0 +1,210 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	taskSvc "taskchord/internal/pkg/task/svc"
+	"taskchord/internal/pkg/track/ent"
+	"time"
+)
+
+type TrackService struct {
+	db gossiper.Database
+}
+
+// NewTrackService initializes a new time tracking service
+func NewTrackService(db gossiper.Database) *TrackService {
+	return &TrackService{db: db}
+}
+
+// StartTimer starts tracking time on a task; a user can only run one timer at a time
+func (s *TrackService) StartTimer(guildID, userID, id string) (taskEnt.Task, error) {
+	var task taskEnt.Task
+
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		task, err = findTask(tx, guildID, id)
+		if err != nil {
+			return err
+		}
+
+		var running int64
+		err = tx.Model(&ent.Entry{}).Where("user_id = ? AND ended_at IS NULL", userID).Count(&running).Error
+		if err != nil {
+			return err
+		}
+		if running > 0 {
+			return fmt.Errorf("user %s already has a running timer", userID)
+		}
+
+		return tx.Create(&ent.Entry{
+			GuildID:   guildID,
+			UserID:    userID,
+			TaskID:    task.ID,
+			StartedAt: time.Now(),
+		}).Error
+	})
+
+	return task, err
+}
+
+// StopTimer stops the running timer of a user and returns the task and the tracked session
+func (s *TrackService) StopTimer(userID string) (taskEnt.Task, ent.Entry, error) {
+	var task taskEnt.Task
+	var entry ent.Entry
+
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND ended_at IS NULL", userID).First(&entry).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("user %s has no running timer", userID)
+			}
+			return err
+		}
+
+		if err := stopEntry(tx, &entry, time.Now()); err != nil {
+			return err
+		}
+
+		return tx.First(&task, entry.TaskID).Error
+	})
+
+	return task, entry, err
+}
+
+// LogTime records work on a task that was not timed, ending at the given moment
+func (s *TrackService) LogTime(guildID, userID, id string, duration time.Duration, endedAt time.Time) (taskEnt.Task, error) {
+	var task taskEnt.Task
+
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		task, err = findTask(tx, guildID, id)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&ent.Entry{
+			GuildID:   guildID,
+			UserID:    userID,
+			TaskID:    task.ID,
+			StartedAt: endedAt.Add(-duration),
+			EndedAt:   &endedAt,
+			Seconds:   int64(duration.Seconds()),
+		}).Error
+	})
+
+	return task, err
+}
+
+// StopIdleTimers stops every timer that has been running longer than the limit,
+// counting only the limit itself. It returns the stopped sessions.
+func (s *TrackService) StopIdleTimers(limit time.Duration) ([]ent.Entry, error) {
+	var entries []ent.Entry
+
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("ended_at IS NULL AND started_at < ?", time.Now().Add(-limit)).Find(&entries).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range entries {
+			if err := stopEntry(tx, &entries[i], entries[i].StartedAt.Add(limit)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return entries, err
+}
+
+// GetSpentSeconds sums the finished sessions of each of the given tasks, keyed by task primary key
+func (s *TrackService) GetSpentSeconds(taskIDs []uint) (map[uint]int64, error) {
+	var totals []struct {
+		TaskID  uint
+		Seconds int64
+	}
+	err := s.db.GetDB().Model(&ent.Entry{}).
+		Select("task_id, SUM(seconds) AS seconds").
+		Where("task_id IN ? AND ended_at IS NOT NULL", taskIDs).
+		Group("task_id").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	spent := make(map[uint]int64, len(totals))
+	for _, total := range totals {
+		spent[total.TaskID] = total.Seconds
+	}
+	return spent, nil
+}
+
+// GetTimesheet breaks down the finished sessions a user started within [from, to) per local day and task
+func (s *TrackService) GetTimesheet(guildID, userID string, from, to time.Time, location *time.Location) (ent.Timesheet, error) {
+	timesheet := ent.Timesheet{UserID: userID, From: from, To: to}
+
+	var rows []struct {
+		taskEnt.Task
+		StartedAt time.Time
+		Seconds   int64
+	}
+	err := s.db.GetDB().Model(&ent.Entry{}).
+		Select("tasks.task_id_in_guild, tasks.project_key, tasks.project_number, tasks.title, entries.started_at, entries.seconds").
+		Joins("JOIN tasks ON tasks.id = entries.task_id").
+		Where("entries.guild_id = ? AND entries.user_id = ? AND entries.ended_at IS NOT NULL", guildID, userID).
+		Where("entries.started_at >= ? AND entries.started_at < ?", from, to).
+		Order("entries.started_at ASC, tasks.task_id_in_guild ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return timesheet, err
+	}
+
+	// Rows are ordered by time, so days come out in order; tasks keep the order they were first worked on
+	for _, row := range rows {
+		local := row.StartedAt.In(location)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+
+		if len(timesheet.Days) == 0 || !timesheet.Days[len(timesheet.Days)-1].Date.Equal(date) {
+			timesheet.Days = append(timesheet.Days, ent.TimesheetDay{Date: date})
+		}
+		day := &timesheet.Days[len(timesheet.Days)-1]
+
+		found := false
+		for i := range day.Tasks {
+			if day.Tasks[i].TaskIdInGuild == row.TaskIdInGuild {
+				day.Tasks[i].Seconds += row.Seconds
+				found = true
+				break
+			}
+		}
+		if !found {
+			day.Tasks = append(day.Tasks, ent.TimesheetTask{TaskIdInGuild: row.TaskIdInGuild, Reference: row.Reference(), Title: row.Title, Seconds: row.Seconds})
+		}
+
+		day.Seconds += row.Seconds
+		timesheet.Seconds += row.Seconds
+	}
+
+	return timesheet, nil
+}
+
+// findTask looks up a task by its reference within a guild
+func findTask(tx *gorm.DB, guildID, id string) (taskEnt.Task, error) {
+	var task taskEnt.Task
+	err := tx.Scopes(taskSvc.ByReference(guildID, id)).First(&task).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return task, fmt.Errorf("task with ID %s does not exist", id)
+	}
+	return task, err
+}
+
+// stopEntry ends a running session at the given moment
+func stopEntry(tx *gorm.DB, entry *ent.Entry, endedAt time.Time) error {
+	entry.EndedAt = &endedAt
+	entry.Seconds = int64(endedAt.Sub(entry.StartedAt).Seconds())
+	return tx.Save(entry).Error
+}
@@ -0,0 +1,56 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	guildSvc "taskchord/internal/pkg/guild/svc"
+	"taskchord/internal/pkg/user/svc"
+	"time"
+)
+
+type UserController struct {
+	userService  *svc.UserService
+	guildService *guildSvc.GuildService
+}
+
+// NewUserController creates a new user controller
+func NewUserController(userService *svc.UserService, guildService *guildSvc.GuildService) *UserController {
+	return &UserController{userService: userService, guildService: guildService}
+}
+
+// SetTimezone validates and stores the time zone of a user; "" clears it
+func (c *UserController) SetTimezone(userID, timezone string) error {
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			log.Println("Controller error: Invalid time zone:", err)
+			return fmt.Errorf("invalid time zone %q", timezone)
+		}
+	}
+
+	err := c.userService.SetTimezone(userID, timezone)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// GetLocation resolves the time zone of a user in a guild: their own zone if set,
+// otherwise the guild's default, otherwise UTC
+func (c *UserController) GetLocation(guildID, userID string) *time.Location {
+	settings, err := c.userService.GetSettings(userID)
+	if err != nil {
+		log.Println("Controller error:", err)
+	} else if settings.Timezone != "" {
+		if location, err := time.LoadLocation(settings.Timezone); err == nil {
+			return location
+		}
+	}
+
+	guildSettings, err := c.guildService.GetSettings(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return time.UTC
+	}
+	return guildSettings.Location()
+}
@@ -0,0 +1,12 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+)
+
+// Settings represents personal configuration of a user across all guilds for GORM
+type Settings struct {
+	gorm.Model
+	UserID   string `gorm:"not null;uniqueIndex" json:"user_id"`
+	Timezone string `gorm:"type:varchar(64)" json:"timezone"` // IANA time zone; empty falls back to the guild's
+}
@@ -0,0 +1,36 @@
+package svc
+
+import (
+	"errors"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	"taskchord/internal/pkg/user/ent"
+)
+
+type UserService struct {
+	db gossiper.Database
+}
+
+// NewUserService initializes a new user service
+func NewUserService(db gossiper.Database) *UserService {
+	return &UserService{db: db}
+}
+
+// GetSettings returns the settings of a user, or empty settings if none were saved yet
+func (s *UserService) GetSettings(userID string) (ent.Settings, error) {
+	var settings ent.Settings
+	err := s.db.GetDB().Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ent.Settings{UserID: userID}, nil
+	}
+	return settings, err
+}
+
+// SetTimezone stores the time zone of a user; an empty zone falls back to the guild's
+func (s *UserService) SetTimezone(userID, timezone string) error {
+	settings := ent.Settings{UserID: userID}
+	return s.db.GetDB().
+		Where("user_id = ?", userID).
+		Assign(map[string]any{"timezone": timezone}).
+		FirstOrCreate(&settings).Error
+}
@@ -0,0 +1,104 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"taskchord/internal/pkg/view/ent"
+	"taskchord/internal/pkg/view/svc"
+)
+
+type ViewController struct {
+	viewService *svc.ViewService
+}
+
+// NewViewController creates a new view controller
+func NewViewController(viewService *svc.ViewService) *ViewController {
+	return &ViewController{viewService: viewService}
+}
+
+// SaveView validates and stores a view. Shared views are stored without an owner.
+func (c *ViewController) SaveView(guildID, userID, name, query string, shared bool) (ent.View, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 50 {
+		log.Println("Controller error: View name must have between 1 and 50 characters")
+		return ent.View{}, fmt.Errorf("view name must have between 1 and 50 characters")
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" || len(query) > 500 {
+		log.Println("Controller error: View query must have between 1 and 500 characters")
+		return ent.View{}, fmt.Errorf("view query must have between 1 and 500 characters")
+	}
+	if _, err := taskEnt.ParseQuery(query); err != nil {
+		log.Println("Controller error:", err)
+		return ent.View{}, err
+	}
+
+	view := ent.View{GuildID: guildID, UserID: userID, Name: name, Query: query}
+	if shared {
+		view.UserID = ""
+	}
+
+	view, err := c.viewService.SaveView(view)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.View{}, err
+	}
+	return view, nil
+}
+
+// GetView finds a view a member can use by name
+func (c *ViewController) GetView(guildID, userID, name string) (ent.View, error) {
+	view, err := c.viewService.GetView(guildID, userID, name)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.View{}, err
+	}
+	return view, nil
+}
+
+// GetViewByID retrieves a view by its primary key
+func (c *ViewController) GetViewByID(id uint) (ent.View, error) {
+	return c.viewService.GetViewByID(id)
+}
+
+// GetViews lists the views a member can use
+func (c *ViewController) GetViews(guildID, userID string) ([]ent.View, error) {
+	views, err := c.viewService.GetViews(guildID, userID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return nil, err
+	}
+	return views, nil
+}
+
+// DeleteView removes a view and the boards pinned from it
+func (c *ViewController) DeleteView(view ent.View) error {
+	if err := c.viewService.DeleteView(view); err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
+
+// AddPin records a board message made from a view
+func (c *ViewController) AddPin(view ent.View, userID, channelID, messageID string) (ent.Pin, error) {
+	pin, err := c.viewService.AddPin(ent.Pin{GuildID: view.GuildID, ViewID: view.ID, UserID: userID, ChannelID: channelID, MessageID: messageID})
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Pin{}, err
+	}
+	return pin, nil
+}
+
+// GetPins retrieves every pinned board
+func (c *ViewController) GetPins() ([]ent.Pin, error) {
+	return c.viewService.GetPins()
+}
+
+// DeletePin forgets a pinned board
+func (c *ViewController) DeletePin(pin ent.Pin) error {
+	return c.viewService.DeletePin(pin)
+}
@@ -0,0 +1,31 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+)
+
+// View is a task query saved under a name, for GORM. Personal views belong to one member;
+// shared views have no owner and are available to everyone in the guild.
+type View struct {
+	gorm.Model
+	GuildID string `gorm:"not null;uniqueIndex:idx_view_name" json:"guild_id"`
+	UserID  string `gorm:"not null;uniqueIndex:idx_view_name" json:"user_id"` // Empty for shared views
+	Name    string `gorm:"type:varchar(50);not null;uniqueIndex:idx_view_name" json:"name"`
+	Query   string `gorm:"type:text;not null" json:"query"` // Task query such as status:open tag:backend
+}
+
+// Shared reports whether a view is available to the whole guild
+func (v View) Shared() bool {
+	return v.UserID == ""
+}
+
+// Pin is a board message kept up to date with the tasks of a view, for GORM
+type Pin struct {
+	gorm.Model
+	GuildID   string `gorm:"not null;index" json:"guild_id"`
+	ViewID    uint   `gorm:"not null;index" json:"view_id"`
+	UserID    string `gorm:"not null" json:"user_id"` // Member who pinned the board; @me in the query refers to them
+	ChannelID string `gorm:"not null" json:"channel_id"`
+	MessageID string `gorm:"not null;uniqueIndex" json:"message_id"`
+	View      View   `json:"view"`
+}
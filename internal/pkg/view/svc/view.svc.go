@@ -0,0 +1,104 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	digestEnt "taskchord/internal/pkg/digest/ent"
+	"taskchord/internal/pkg/view/ent"
+)
+
+type ViewService struct {
+	db gossiper.Database
+}
+
+// NewViewService initializes a new view service
+func NewViewService(db gossiper.Database) *ViewService {
+	return &ViewService{db: db}
+}
+
+// SaveView stores a view, replacing the view of the same name and owner
+func (s *ViewService) SaveView(view ent.View) (ent.View, error) {
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		var existing ent.View
+		err := tx.Where("guild_id = ? AND user_id = ? AND LOWER(name) = LOWER(?)", view.GuildID, view.UserID, view.Name).
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&view).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		// Updating in place keeps the boards pinned from the view
+		existing.Name = view.Name
+		existing.Query = view.Query
+		view = existing
+		return tx.Save(&view).Error
+	})
+	return view, err
+}
+
+// GetView finds a view a member can use by name, ignoring case. Their own views come before shared ones.
+func (s *ViewService) GetView(guildID, userID, name string) (ent.View, error) {
+	var view ent.View
+	err := s.db.GetDB().
+		Where("guild_id = ? AND user_id IN ? AND LOWER(name) = LOWER(?)", guildID, []string{userID, ""}, name).
+		Order("user_id DESC").
+		First(&view).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return view, fmt.Errorf("view %s does not exist", name)
+	}
+	return view, err
+}
+
+// GetViewByID retrieves a view by its primary key
+func (s *ViewService) GetViewByID(id uint) (ent.View, error) {
+	var view ent.View
+	err := s.db.GetDB().First(&view, id).Error
+	return view, err
+}
+
+// GetViews lists the views a member can use: their own, then the shared ones, each by name
+func (s *ViewService) GetViews(guildID, userID string) ([]ent.View, error) {
+	var views []ent.View
+	err := s.db.GetDB().
+		Where("guild_id = ? AND user_id IN ?", guildID, []string{userID, ""}).
+		Order("user_id DESC, name").
+		Find(&views).Error
+	return views, err
+}
+
+// DeleteView removes a view and unpins the boards made from it. Digests that listed
+// the view go on without it.
+func (s *ViewService) DeleteView(view ent.View) error {
+	return s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("view_id = ?", view.ID).Delete(&ent.Pin{}).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&digestEnt.Subscription{}).Where("view_id = ?", view.ID).Update("view_id", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&view).Error
+	})
+}
+
+// AddPin records a board message made from a view
+func (s *ViewService) AddPin(pin ent.Pin) (ent.Pin, error) {
+	err := s.db.GetDB().Create(&pin).Error
+	return pin, err
+}
+
+// GetPins retrieves every pinned board across all guilds, along with its view
+func (s *ViewService) GetPins() ([]ent.Pin, error) {
+	var pins []ent.Pin
+	err := s.db.GetDB().Preload("View").Find(&pins).Error
+	return pins, err
+}
+
+// DeletePin forgets a pinned board, e.g. once its message was deleted
+func (s *ViewService) DeletePin(pin ent.Pin) error {
+	return s.db.GetDB().Unscoped().Delete(&pin).Error
+}
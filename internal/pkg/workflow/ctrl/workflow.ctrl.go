@@ -0,0 +1,115 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"taskchord/internal/pkg/workflow/ent"
+	"taskchord/internal/pkg/workflow/svc"
+)
+
+// maxStates keeps every state selectable and every board column visible
+const maxStates = 20
+
+type WorkflowController struct {
+	workflowService *svc.WorkflowService
+}
+
+// NewWorkflowController creates a new workflow controller
+func NewWorkflowController(workflowService *svc.WorkflowService) *WorkflowController {
+	return &WorkflowController{workflowService: workflowService}
+}
+
+// GetWorkflow retrieves the workflow of a guild
+func (c *WorkflowController) GetWorkflow(guildID string) (ent.Workflow, error) {
+	workflow, err := c.workflowService.GetWorkflow(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Workflow{}, err
+	}
+	return workflow, nil
+}
+
+// SetStates validates a comma separated list of states in board order and stores it.
+// The terminal state defaults to the last one.
+func (c *WorkflowController) SetStates(guildID, states, terminal string) (ent.Workflow, error) {
+	var names []string
+	for _, name := range strings.Split(states, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) > 20 {
+			log.Println("Controller error: Status names must have between 1 and 20 characters")
+			return ent.Workflow{}, fmt.Errorf("status names must have between 1 and 20 characters")
+		}
+		for _, other := range names {
+			if strings.EqualFold(other, name) {
+				log.Println("Controller error: Status names must be unique")
+				return ent.Workflow{}, fmt.Errorf("status %s is listed twice", name)
+			}
+		}
+		names = append(names, name)
+	}
+	if len(names) < 2 || len(names) > maxStates {
+		log.Println("Controller error: A workflow must have between 2 and 20 states")
+		return ent.Workflow{}, fmt.Errorf("a workflow must have between 2 and %d states", maxStates)
+	}
+
+	terminalName := names[len(names)-1]
+	if terminal = strings.TrimSpace(terminal); terminal != "" {
+		terminalName = ""
+		for _, name := range names {
+			if strings.EqualFold(name, terminal) {
+				terminalName = name
+			}
+		}
+		if terminalName == "" {
+			log.Println("Controller error: Terminal status must be one of the states")
+			return ent.Workflow{}, fmt.Errorf("terminal status %s is not one of the states", terminal)
+		}
+	}
+	if terminalName == names[0] {
+		log.Println("Controller error: New tasks cannot start in the terminal status")
+		return ent.Workflow{}, fmt.Errorf("new tasks cannot start in the terminal status")
+	}
+
+	workflow, err := c.workflowService.SetStates(guildID, names, terminalName)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Workflow{}, err
+	}
+	return workflow, nil
+}
+
+// AllowTransition lets tasks move from one state to another
+func (c *WorkflowController) AllowTransition(guildID, from, to string) (ent.Workflow, error) {
+	if strings.EqualFold(from, to) {
+		log.Println("Controller error: A transition needs two different states")
+		return ent.Workflow{}, fmt.Errorf("a transition needs two different states")
+	}
+
+	workflow, err := c.workflowService.AllowTransition(guildID, from, to)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Workflow{}, err
+	}
+	return workflow, nil
+}
+
+// ForbidTransition stops tasks from moving from one state to another
+func (c *WorkflowController) ForbidTransition(guildID, from, to string) (ent.Workflow, error) {
+	workflow, err := c.workflowService.ForbidTransition(guildID, from, to)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return ent.Workflow{}, err
+	}
+	return workflow, nil
+}
+
+// ResetWorkflow goes back to the default Open and Done workflow
+func (c *WorkflowController) ResetWorkflow(guildID string) error {
+	err := c.workflowService.ResetWorkflow(guildID)
+	if err != nil {
+		log.Println("Controller error:", err)
+		return err
+	}
+	return nil
+}
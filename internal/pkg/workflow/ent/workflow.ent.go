@@ -0,0 +1,95 @@
+package ent
+
+import (
+	"gorm.io/gorm"
+	"strings"
+)
+
+// State is one status of a guild's workflow, for GORM
+type State struct {
+	gorm.Model
+	GuildID  string `gorm:"not null;uniqueIndex:idx_workflow_state" json:"guild_id"`
+	Name     string `gorm:"type:varchar(20);not null;uniqueIndex:idx_workflow_state" json:"name"`
+	Position int    `gorm:"not null" json:"position"`               // Order of the board columns; 0 is where new tasks start
+	Terminal bool   `gorm:"not null;default:false" json:"terminal"` // Tasks reaching this state are done
+}
+
+// Transition allows tasks to move from one state to another, for GORM
+type Transition struct {
+	gorm.Model
+	GuildID string `gorm:"not null;uniqueIndex:idx_workflow_transition" json:"guild_id"`
+	From    string `gorm:"type:varchar(20);not null;uniqueIndex:idx_workflow_transition" json:"from"`
+	To      string `gorm:"type:varchar(20);not null;uniqueIndex:idx_workflow_transition" json:"to"`
+}
+
+// Workflow holds the states of a guild in board order and the moves allowed between them
+type Workflow struct {
+	States      []State
+	Transitions []Transition
+}
+
+// DefaultWorkflow is used by guilds that did not define their own
+var DefaultWorkflow = Workflow{
+	States: []State{
+		{Name: "Open", Position: 0},
+		{Name: "Done", Position: 1, Terminal: true},
+	},
+	Transitions: []Transition{
+		{From: "Open", To: "Done"},
+		{From: "Done", To: "Open"},
+	},
+}
+
+// Find looks up a state by name, ignoring case
+func (w Workflow) Find(name string) (State, bool) {
+	for _, state := range w.States {
+		if strings.EqualFold(state.Name, name) {
+			return state, true
+		}
+	}
+	return State{}, false
+}
+
+// Initial is the state new tasks start in
+func (w Workflow) Initial() State {
+	return w.States[0]
+}
+
+// Terminal is the state of done tasks
+func (w Workflow) Terminal() State {
+	for _, state := range w.States {
+		if state.Terminal {
+			return state
+		}
+	}
+	return w.States[len(w.States)-1]
+}
+
+// Allows reports whether tasks may move straight from one state to another
+func (w Workflow) Allows(from, to string) bool {
+	for _, transition := range w.Transitions {
+		if strings.EqualFold(transition.From, from) && strings.EqualFold(transition.To, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next lists the states a task in the given state may move to, in board order
+func (w Workflow) Next(from string) []State {
+	var next []State
+	for _, state := range w.States {
+		if w.Allows(from, state.Name) {
+			next = append(next, state)
+		}
+	}
+	return next
+}
+
+// Rank orders statuses by their position in the workflow; unknown ones come last
+func (w Workflow) Rank(name string) int {
+	if state, ok := w.Find(name); ok {
+		return state.Position
+	}
+	return len(w.States)
+}
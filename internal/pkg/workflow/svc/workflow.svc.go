@@ -0,0 +1,217 @@
+package svc
+
+import (
+	"fmt"
+	gossiper "github.com/pieceowater-dev/lotof.lib.gossiper/v2"
+	"gorm.io/gorm"
+	taskEnt "taskchord/internal/pkg/task/ent"
+	"taskchord/internal/pkg/workflow/ent"
+)
+
+type WorkflowService struct {
+	db gossiper.Database
+}
+
+// NewWorkflowService initializes a new workflow service
+func NewWorkflowService(db gossiper.Database) *WorkflowService {
+	return &WorkflowService{db: db}
+}
+
+// GetWorkflow retrieves the workflow of a guild, or the default workflow when it has none
+func (s *WorkflowService) GetWorkflow(guildID string) (ent.Workflow, error) {
+	return getWorkflow(s.db.GetDB(), guildID)
+}
+
+// Initial retrieves the state new tasks of a guild start in
+func (s *WorkflowService) Initial(guildID string) (ent.State, error) {
+	workflow, err := s.GetWorkflow(guildID)
+	if err != nil {
+		return ent.State{}, err
+	}
+	return workflow.Initial(), nil
+}
+
+// Move checks that a task may go from one state to another and returns the state it ends up in.
+// Tasks in a state that is no longer part of the workflow may move anywhere.
+func (s *WorkflowService) Move(guildID, from, to string) (ent.State, error) {
+	workflow, err := s.GetWorkflow(guildID)
+	if err != nil {
+		return ent.State{}, err
+	}
+
+	target, ok := workflow.Find(to)
+	if !ok {
+		return ent.State{}, fmt.Errorf("status %s does not exist", to)
+	}
+	if target.Name == from {
+		return target, nil
+	}
+	if _, known := workflow.Find(from); known && !workflow.Allows(from, target.Name) {
+		return ent.State{}, fmt.Errorf("tasks cannot move from %s to %s", from, target.Name)
+	}
+	return target, nil
+}
+
+// SetStates replaces the states of a guild's workflow, in board order, with one of them terminal.
+// Each state may then move to the next and back to the previous one. Tasks in a state that
+// disappears go back to the first state, and done tasks move to the terminal state.
+func (s *WorkflowService) SetStates(guildID string, names []string, terminal string) (ent.Workflow, error) {
+	var workflow ent.Workflow
+	for position, name := range names {
+		workflow.States = append(workflow.States, ent.State{Name: name, Position: position, Terminal: name == terminal})
+		if position > 0 {
+			workflow.Transitions = append(workflow.Transitions,
+				ent.Transition{From: names[position-1], To: name},
+				ent.Transition{From: name, To: names[position-1]})
+		}
+	}
+
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := saveWorkflow(tx, guildID, workflow); err != nil {
+			return err
+		}
+		return remapTasks(tx, guildID, workflow)
+	})
+	return workflow, err
+}
+
+// AllowTransition lets tasks move from one state to another
+func (s *WorkflowService) AllowTransition(guildID, from, to string) (ent.Workflow, error) {
+	return s.changeTransitions(guildID, from, to, func(workflow *ent.Workflow, from, to string) error {
+		if workflow.Allows(from, to) {
+			return fmt.Errorf("tasks can already move from %s to %s", from, to)
+		}
+		workflow.Transitions = append(workflow.Transitions, ent.Transition{From: from, To: to})
+		return nil
+	})
+}
+
+// ForbidTransition stops tasks from moving from one state to another
+func (s *WorkflowService) ForbidTransition(guildID, from, to string) (ent.Workflow, error) {
+	return s.changeTransitions(guildID, from, to, func(workflow *ent.Workflow, from, to string) error {
+		if !workflow.Allows(from, to) {
+			return fmt.Errorf("tasks cannot move from %s to %s", from, to)
+		}
+		var transitions []ent.Transition
+		for _, transition := range workflow.Transitions {
+			if transition.From != from || transition.To != to {
+				transitions = append(transitions, transition)
+			}
+		}
+		workflow.Transitions = transitions
+		return nil
+	})
+}
+
+// ResetWorkflow goes back to the default workflow
+func (s *WorkflowService) ResetWorkflow(guildID string) error {
+	return s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("guild_id = ?", guildID).Delete(&ent.State{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("guild_id = ?", guildID).Delete(&ent.Transition{}).Error; err != nil {
+			return err
+		}
+		return remapTasks(tx, guildID, ent.DefaultWorkflow)
+	})
+}
+
+// changeTransitions applies a change to the transitions between two existing states and stores the workflow
+func (s *WorkflowService) changeTransitions(guildID, from, to string, change func(workflow *ent.Workflow, from, to string) error) (ent.Workflow, error) {
+	var workflow ent.Workflow
+	err := s.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		workflow, err = getWorkflow(tx, guildID)
+		if err != nil {
+			return err
+		}
+
+		fromState, ok := workflow.Find(from)
+		if !ok {
+			return fmt.Errorf("status %s does not exist", from)
+		}
+		toState, ok := workflow.Find(to)
+		if !ok {
+			return fmt.Errorf("status %s does not exist", to)
+		}
+		if err := change(&workflow, fromState.Name, toState.Name); err != nil {
+			return err
+		}
+
+		// Guilds on the default workflow get it stored so the change sticks
+		return saveWorkflow(tx, guildID, workflow)
+	})
+	return workflow, err
+}
+
+// getWorkflow reads the stored workflow of a guild
+func getWorkflow(db *gorm.DB, guildID string) (ent.Workflow, error) {
+	var workflow ent.Workflow
+	if err := db.Where("guild_id = ?", guildID).Order("position").Find(&workflow.States).Error; err != nil {
+		return workflow, err
+	}
+	if len(workflow.States) == 0 {
+		return ent.Workflow{
+			States:      append([]ent.State(nil), ent.DefaultWorkflow.States...),
+			Transitions: append([]ent.Transition(nil), ent.DefaultWorkflow.Transitions...),
+		}, nil
+	}
+
+	err := db.Where("guild_id = ?", guildID).Order("id").Find(&workflow.Transitions).Error
+	return workflow, err
+}
+
+// saveWorkflow stores a whole workflow in place of the previous one
+func saveWorkflow(tx *gorm.DB, guildID string, workflow ent.Workflow) error {
+	if err := tx.Unscoped().Where("guild_id = ?", guildID).Delete(&ent.State{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("guild_id = ?", guildID).Delete(&ent.Transition{}).Error; err != nil {
+		return err
+	}
+
+	states := make([]ent.State, len(workflow.States))
+	for position, state := range workflow.States {
+		states[position] = ent.State{GuildID: guildID, Name: state.Name, Position: position, Terminal: state.Terminal}
+	}
+	if err := tx.Create(&states).Error; err != nil {
+		return err
+	}
+
+	if len(workflow.Transitions) == 0 {
+		return nil
+	}
+	transitions := make([]ent.Transition, len(workflow.Transitions))
+	for index, transition := range workflow.Transitions {
+		transitions[index] = ent.Transition{GuildID: guildID, From: transition.From, To: transition.To}
+	}
+	return tx.Create(&transitions).Error
+}
+
+// remapTasks moves the tasks of a guild into states of its new workflow: done tasks go to the
+// terminal state, and open tasks whose state no longer exists go back to the first one
+func remapTasks(tx *gorm.DB, guildID string, workflow ent.Workflow) error {
+	var open []string
+	for _, state := range workflow.States {
+		// Keep tasks whose state only changed case
+		err := tx.Model(&taskEnt.Task{}).
+			Where("guild_id = ? AND LOWER(status) = LOWER(?) AND status <> ?", guildID, state.Name, state.Name).
+			Update("status", state.Name).Error
+		if err != nil {
+			return err
+		}
+		if !state.Terminal {
+			open = append(open, state.Name)
+		}
+	}
+
+	err := tx.Model(&taskEnt.Task{}).
+		Where("guild_id = ? AND completed_at IS NOT NULL", guildID).
+		Update("status", workflow.Terminal().Name).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&taskEnt.Task{}).
+		Where("guild_id = ? AND completed_at IS NULL AND status NOT IN ?", guildID, open).
+		Update("status", workflow.Initial().Name).Error
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"bytes"
+	"strings"
+	"taskchord/internal/pkg/calendar/ent"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dayLayout   = "20060102"
+	stampLayout = "20060102T150405Z"
+	lineLimit   = 75 // Octets per line before RFC 5545 asks for folding
+)
+
+// renderICS writes a calendar in the iCalendar format of RFC 5545
+func renderICS(calendar ent.Calendar, now time.Time) []byte {
+	var buf bytes.Buffer
+	line := func(name, value string) {
+		writeFolded(&buf, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//TaskChord//Calendar//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeText(calendar.Name))
+	for _, event := range calendar.Events {
+		stamp := event.UpdatedAt
+		if stamp.IsZero() {
+			stamp = now
+		}
+		day := event.Day.UTC()
+
+		line("BEGIN", "VEVENT")
+		line("UID", event.UID)
+		line("DTSTAMP", stamp.UTC().Format(stampLayout))
+		// Whole-day events end on the following day, which is exclusive
+		line("DTSTART;VALUE=DATE", day.Format(dayLayout))
+		line("DTEND;VALUE=DATE", day.AddDate(0, 0, 1).Format(dayLayout))
+		line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escapeText(event.Description))
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return buf.Bytes()
+}
+
+// escapeText escapes the characters that have a meaning in iCalendar text values
+func escapeText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(text)
+}
+
+// writeFolded ends a content line with CRLF, breaking it into continuation lines that start with a space
+// so that no line is longer than 75 octets. Lines are only broken between characters.
+func writeFolded(buf *bytes.Buffer, content string) {
+	limit := lineLimit
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		buf.WriteString(content[:cut])
+		buf.WriteString("\r\n ")
+		content = content[cut:]
+		limit = lineLimit - 1 // The leading space counts towards the next line
+	}
+	buf.WriteString(content)
+	buf.WriteString("\r\n")
+}
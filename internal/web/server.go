@@ -0,0 +1,76 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strings"
+	"taskchord/internal/pkg/calendar/ctrl"
+	"time"
+)
+
+// Server serves calendar feeds over HTTP next to the bot
+type Server struct {
+	server             *http.Server
+	calendarController *ctrl.CalendarController
+}
+
+// NewServer creates a server listening on addr, such as :8080
+func NewServer(addr string, calendarController *ctrl.CalendarController) *Server {
+	s := &Server{calendarController: calendarController}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /calendar/{token}", s.handleCalendar)
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	return s
+}
+
+// Start listens in the background until Stop is called
+func (s *Server) Start() {
+	go func() {
+		log.Printf("Serving calendar feeds on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Calendar server stopped: %v", err)
+		}
+	}()
+}
+
+// Stop waits briefly for requests in flight, then closes the listener
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down calendar server: %v", err)
+	}
+}
+
+// handleCalendar answers /calendar/<token>.ics with the feed behind the token
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSuffix(r.PathValue("token"), ".ics")
+
+	calendar, err := s.calendarController.GetCalendar(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Revoked and mistyped tokens look the same, so nothing tells a guess apart from a reset feed
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		// Anything else is on our side; calendar apps keep subscriptions that fail this way and try again later
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if _, err := w.Write(renderICS(calendar, time.Now())); err != nil {
+		log.Printf("Error writing calendar: %v", err)
+	}
+}